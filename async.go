@@ -0,0 +1,67 @@
+package diag
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// Async forwards diagnostics to an inner Interface from a background
+// goroutine. See NewAsync.
+type Async struct {
+	mu      sync.RWMutex
+	closed  bool
+	queue   chan Diagnostic
+	done    chan struct{}
+	dropped int64
+}
+
+// NewAsync creates an Interface that queues each message, after masking, for
+// a background goroutine to forward to inner. When the queue already holds
+// queueSize messages, further messages are dropped and counted rather than
+// blocking the caller.
+//
+// Call Close to forward the remaining queued messages and stop the goroutine.
+func NewAsync(inner Interface, queueSize int) (*Async, Interface) {
+	a := &Async{
+		queue: make(chan Diagnostic, queueSize),
+		done:  make(chan struct{}),
+	}
+	go func() {
+		defer close(a.done)
+		for m := range a.queue {
+			forward(inner, m)
+		}
+	}()
+	return a, &funnel{emit: a.emit}
+}
+
+func (a *Async) emit(m Diagnostic) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	if !a.closed {
+		select {
+		case a.queue <- m:
+			return
+		default:
+		}
+	}
+	atomic.AddInt64(&a.dropped, 1)
+}
+
+// Dropped returns the number of messages dropped because the queue was full,
+// or because they were issued after Close.
+func (a *Async) Dropped() int {
+	return int(atomic.LoadInt64(&a.dropped))
+}
+
+// Close stops accepting messages, and waits for the queued messages to be
+// forwarded. It is safe to call more than once.
+func (a *Async) Close() {
+	a.mu.Lock()
+	if !a.closed {
+		a.closed = true
+		close(a.queue)
+	}
+	a.mu.Unlock()
+	<-a.done
+}
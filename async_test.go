@@ -0,0 +1,79 @@
+package diag_test
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/mutility/diag"
+)
+
+// slow is an Interface that blocks in Print until released.
+type slow struct {
+	mu      sync.Mutex
+	release chan struct{}
+	prints  []string
+}
+
+func (s *slow) Debug(...interface{})   {}
+func (s *slow) Warning(...interface{}) {}
+func (s *slow) Error(...interface{})   {}
+func (s *slow) Print(a ...interface{}) {
+	<-s.release
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.prints = append(s.prints, fmt.Sprint(a...))
+}
+
+func TestAsync(t *testing.T) {
+	inner := &slow{release: make(chan struct{})}
+	a, d := diag.NewAsync(inner, 2)
+	diag.MaskValue(d, "secret")
+	t.Cleanup(func() { diag.Forget(d) })
+
+	// The goroutine takes the first message and blocks; two more fill the
+	// queue, and the rest are dropped.
+	diag.Print(d, "first secret")
+	sent := 1
+	for a.Dropped() == 0 {
+		diag.Print(d, "message", sent)
+		sent++
+	}
+	close(inner.release)
+	a.Close()
+	a.Close()
+
+	inner.mu.Lock()
+	defer inner.mu.Unlock()
+	if got := len(inner.prints) + a.Dropped(); got != sent {
+		t.Errorf("forwarded %d + dropped %d; want %d", len(inner.prints), a.Dropped(), sent)
+	}
+	if got, want := inner.prints[0], "first ***"; got != want {
+		t.Errorf("first: got %q; want %q", got, want)
+	}
+
+	diag.Print(d, "after close")
+	if got := a.Dropped(); got != sent-len(inner.prints)+1 {
+		t.Errorf("after close: dropped %d; want %d", got, sent-len(inner.prints)+1)
+	}
+}
+
+func TestAsyncConcurrent(t *testing.T) {
+	sb := &lockedBuilder{}
+	a, d := diag.NewAsync(diag.NewWriter(sb), 1000)
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				diag.Warningf(d, "%d.%d", i, j)
+			}
+		}(i)
+	}
+	wg.Wait()
+	a.Close()
+	if got := sb.lines() + a.Dropped(); got != 100 {
+		t.Errorf("forwarded %d + dropped %d; want 100", sb.lines(), a.Dropped())
+	}
+}
@@ -0,0 +1,29 @@
+package diag
+
+import (
+	"strconv"
+	"sync/atomic"
+)
+
+// Attempts labels the diagnostics of successive attempts, such as those of a
+// retry loop. See NewAttempts.
+type Attempts struct {
+	inner Interface
+	n     int64
+}
+
+// NewAttempts creates an Attempts whose views forward to inner.
+func NewAttempts(inner Interface) *Attempts {
+	return &Attempts{inner: inner}
+}
+
+// Next returns an Interface for the next attempt, which prefixes each message
+// with "[attempt N] " before forwarding it to inner. N is 1 for the first call
+// to Next, and increases by one for each call after.
+func (a *Attempts) Next() Interface {
+	prefix := "[attempt " + strconv.FormatInt(atomic.AddInt64(&a.n, 1), 10) + "] "
+	return &funnel{emit: func(m Diagnostic) {
+		m.Msg = prefix + m.Msg
+		forward(a.inner, m)
+	}}
+}
@@ -0,0 +1,27 @@
+package diag_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mutility/diag"
+)
+
+func TestAttempts(t *testing.T) {
+	sb := &strings.Builder{}
+	a := diag.NewAttempts(diag.NewWriter(sb))
+	first := a.Next()
+	diag.Warning(first, "timed out")
+	second := a.Next()
+	diag.ErrorAt(second, "a.go", 1, 2, "refused")
+	diag.Print(first, "still first") // stable after later calls to Next
+	diag.Infof(a.Next(), "ok after %d", 2)
+
+	want := "[attempt 1] timed out\n" +
+		"[a.go:1.2] [attempt 2] refused\n" +
+		"[attempt 1] still first\n" +
+		"[attempt 3] ok after 2\n"
+	if got := sb.String(); got != want {
+		t.Errorf("got %q; want %q", got, want)
+	}
+}
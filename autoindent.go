@@ -0,0 +1,56 @@
+package diag
+
+import (
+	"strings"
+	"sync/atomic"
+)
+
+// AutoIndent is an Interface that indents messages by a depth controlled
+// with Enter and Leave. See NewAutoIndent.
+type AutoIndent struct {
+	funnel
+	inner  Interface
+	indent string
+	depth  int32
+}
+
+// NewAutoIndent creates an AutoIndent that forwards to inner, indenting each
+// message by two spaces per level of depth unless overridden by WithIndent.
+// This suits tracing recursive functions without explicit groups:
+//
+//     func walk(ai *diag.AutoIndent, n *Node) {
+//         defer ai.Enter()()
+//         diag.Debug(ai, "visiting", n.Name)
+//         ...
+//     }
+//
+func NewAutoIndent(inner Interface, opts ...Option) *AutoIndent {
+	o := newOptions(opts)
+	ai := &AutoIndent{inner: inner, indent: "  "}
+	if o.indent != nil {
+		ai.indent = *o.indent
+	}
+	ai.funnel.emit = ai.emit
+	return ai
+}
+
+// Enter increases the depth by one, and returns Leave for use with defer.
+func (ai *AutoIndent) Enter() func() {
+	atomic.AddInt32(&ai.depth, 1)
+	return ai.Leave
+}
+
+// Leave decreases the depth by one, stopping at zero.
+func (ai *AutoIndent) Leave() {
+	for {
+		depth := atomic.LoadInt32(&ai.depth)
+		if depth <= 0 || atomic.CompareAndSwapInt32(&ai.depth, depth, depth-1) {
+			return
+		}
+	}
+}
+
+func (ai *AutoIndent) emit(m Diagnostic) {
+	m.Msg = strings.Repeat(ai.indent, int(atomic.LoadInt32(&ai.depth))) + m.Msg
+	forward(ai.inner, m)
+}
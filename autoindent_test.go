@@ -0,0 +1,39 @@
+package diag_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mutility/diag"
+)
+
+func TestAutoIndent(t *testing.T) {
+	sb := &strings.Builder{}
+	ai := diag.NewAutoIndent(diag.NewWriterDebug(sb))
+
+	var walk func(depth int)
+	walk = func(depth int) {
+		defer ai.Enter()()
+		diag.Debugf(ai, "depth %d", depth)
+		if depth < 2 {
+			walk(depth + 1)
+		}
+		diag.Print(ai, "done", depth)
+	}
+	diag.Print(ai, "start")
+	walk(0)
+	ai.Leave() // clamped at zero
+	diag.WarningAt(ai, "fn.go", 1, 2, "end")
+
+	want := "start\n" +
+		"  depth 0\n" +
+		"    depth 1\n" +
+		"      depth 2\n" +
+		"      done 2\n" +
+		"    done 1\n" +
+		"  done 0\n" +
+		"[fn.go:1.2] end\n"
+	if got := sb.String(); got != want {
+		t.Errorf("got %q; want %q", got, want)
+	}
+}
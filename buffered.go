@@ -0,0 +1,123 @@
+package diag
+
+import (
+	"bufio"
+	"io"
+	"sort"
+	"sync"
+)
+
+// NewBufferedPrefixed returns a writer that prefixes each line like
+// NewPrefixed, but accumulates its output and writes it to w only once
+// bufSize bytes are pending, or when Close is called. This reduces the number
+// of writes to w for high-volume streams. Close does not close w.
+func NewBufferedPrefixed(w io.Writer, prefix string, bufSize int) io.WriteCloser {
+	buf := bufio.NewWriterSize(w, bufSize)
+	return &bufferedPrefixWriter{buf: buf, pw: NewPrefixed(buf, prefix)}
+}
+
+type bufferedPrefixWriter struct {
+	mu  sync.Mutex
+	buf *bufio.Writer
+	pw  *prefixWriter
+}
+
+func (w *bufferedPrefixWriter) Write(b []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.pw.Write(b)
+}
+
+// Close writes any pending output to the underlying writer.
+func (w *bufferedPrefixWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.Flush()
+}
+
+// Buffered is an Interface that holds diagnostics until Flush replays them
+// sorted by location. See NewBuffered.
+type Buffered struct {
+	funnel
+
+	inner Interface
+	opts  options
+
+	mu    sync.Mutex
+	diags []Diagnostic
+}
+
+// NewBuffered creates a Buffered that holds all diagnostics until Flush, as
+// compilers and linters do to report in source order regardless of the order
+// problems were found. Messages are masked when recorded, both by masks
+// registered on the Buffered and by those registered on inner, so secrets are
+// not held unmasked.
+//
+// By default, messages without a location keep their original positions in
+// the output, and the located messages are sorted among the remaining
+// positions. WithUnlocatedFirst instead flushes them all first, in the order
+// they were emitted.
+func NewBuffered(inner Interface, opts ...Option) *Buffered {
+	b := &Buffered{inner: inner, opts: newOptions(opts)}
+	b.funnel.emit = b.record
+	return b
+}
+
+func (b *Buffered) record(m Diagnostic) {
+	m.Msg = mask(b.inner).replace(m.Msg)
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.diags = append(b.diags, m)
+}
+
+// Flush emits the diagnostics held so far to inner, and forgets them. Located
+// diagnostics are sorted by file, line, and column, and then from most to
+// least severe; those that compare equal keep the order they were emitted.
+func (b *Buffered) Flush() {
+	if h := thelper(b.inner); h != nil {
+		h()
+	}
+	b.mu.Lock()
+	diags := b.diags
+	b.diags = nil
+	b.mu.Unlock()
+
+	var located, slots []int // indexes into diags
+	var unlocated []Diagnostic
+	for i, m := range diags {
+		if m.File != "" {
+			located = append(located, i)
+			slots = append(slots, i)
+		} else if b.opts.unlocatedFirst {
+			unlocated = append(unlocated, m)
+		}
+	}
+	sort.SliceStable(located, func(i, j int) bool {
+		x, y := diags[located[i]], diags[located[j]]
+		switch {
+		case x.File != y.File:
+			return x.File < y.File
+		case x.Line != y.Line:
+			return x.Line < y.Line
+		case x.Col != y.Col:
+			return x.Col < y.Col
+		}
+		return x.Level > y.Level
+	})
+
+	var out []Diagnostic
+	if b.opts.unlocatedFirst {
+		out = unlocated
+		for _, i := range located {
+			out = append(out, diags[i])
+		}
+	} else {
+		out = append(out, diags...)
+		for n, i := range located {
+			out[slots[n]] = diags[i]
+		}
+	}
+	for _, m := range out {
+		forward(b.inner, m)
+	}
+}
@@ -0,0 +1,95 @@
+package diag_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mutility/diag"
+)
+
+// countingWriter records each write it receives.
+type countingWriter struct{ writes []string }
+
+func (w *countingWriter) Write(b []byte) (int, error) {
+	w.writes = append(w.writes, string(b))
+	return len(b), nil
+}
+
+func TestBufferedPrefixed(t *testing.T) {
+	cw := &countingWriter{}
+	w := diag.NewBufferedPrefixed(cw, "E:", 64)
+	d := diag.NewWriters(w, w, w)
+	diag.Error(d, "one")
+	diag.Error(d, "two\nthree")
+	if len(cw.writes) != 0 {
+		t.Fatalf("wrote before close: %q", cw.writes)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	want := "E: one\nE: two\nE: three\n"
+	if got := strings.Join(cw.writes, ""); got != want {
+		t.Errorf("got %q; want %q", got, want)
+	}
+	if len(cw.writes) != 1 {
+		t.Errorf("got %d writes; want 1", len(cw.writes))
+	}
+}
+
+func TestBufferedPrefixedThreshold(t *testing.T) {
+	cw := &countingWriter{}
+	w := diag.NewBufferedPrefixed(cw, "W:", 16)
+	for i := 0; i < 5; i++ {
+		w.Write([]byte("message\n")) // 11 bytes with prefix
+	}
+	if len(cw.writes) == 0 {
+		t.Error("no writes after exceeding the buffer size")
+	}
+	w.Close()
+	want := strings.Repeat("W: message\n", 5)
+	if got := strings.Join(cw.writes, ""); got != want {
+		t.Errorf("got %q; want %q", got, want)
+	}
+}
+
+func TestBuffered(t *testing.T) {
+	emit := func(d diag.Interface) {
+		diag.Print(d, "start")
+		diag.ErrorAt(d, "b.go", 3, 1, "b3")
+		diag.WarningAtf(d, "a.go", 9, 0, "%s", "a9")
+		diag.Warning(d, "middle")
+		diag.DebugAt(d, "a.go", 2, 5, "a2.5 debug")
+		diag.ErrorAt(d, "a.go", 2, 5, "a2.5 error hunter2")
+		diag.PrintAt(d, "a.go", 2, 1, "a2.1")
+		diag.Error(d, "end")
+	}
+	for _, tt := range []struct {
+		name string
+		opts []diag.Option
+		want string
+	}{
+		{"interleaved", nil, "start\n[a.go:2.1] a2.1\n[a.go:2.5] a2.5 error ***\nmiddle\n[a.go:2.5] a2.5 debug\n[a.go:9] a9\n[b.go:3.1] b3\nend\n"},
+		{"first", []diag.Option{diag.WithUnlocatedFirst()}, "start\nmiddle\nend\n[a.go:2.1] a2.1\n[a.go:2.5] a2.5 error ***\n[a.go:2.5] a2.5 debug\n[a.go:9] a9\n[b.go:3.1] b3\n"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			sb := &strings.Builder{}
+			w := diag.NewWriterDebug(sb)
+			diag.MaskValue(w, "hunter2")
+			b := diag.NewBuffered(w, tt.opts...)
+			emit(b)
+			if sb.Len() != 0 {
+				t.Errorf("output before Flush: %q", sb.String())
+			}
+			b.Flush()
+			if got := sb.String(); got != tt.want {
+				t.Errorf("got %q; want %q", got, tt.want)
+			}
+			sb.Reset()
+			b.Flush()
+			if sb.Len() != 0 {
+				t.Errorf("second Flush: %q", sb.String())
+			}
+			diag.Forget(w)
+		})
+	}
+}
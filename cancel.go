@@ -0,0 +1,76 @@
+package diag
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// WithCancelSuppression creates a Context that forwards to d until d is
+// cancelled, after which its output methods do nothing. The first message
+// suppressed, in d or any of its groups, is replaced by a single
+// "output suppressed: context cancelled" print, so that the output does not
+// end without explanation. This quiets a long-running step, such as one in a
+// group, that keeps logging after it was cancelled.
+//
+// Groups are passed through to d, and the Interface passed to fn suppresses
+// output likewise. Groups begun once d is cancelled print no title, and run
+// fn against the result itself.
+func WithCancelSuppression(d Context) Context {
+	return suppressCancelled(d, new(int32))
+}
+
+func suppressCancelled(d Context, noted *int32) *cancelSuppressed {
+	c := &cancelSuppressed{Context: d, inner: d, noted: noted}
+	c.funnel.emit = c.emit
+	return c
+}
+
+type cancelSuppressed struct {
+	funnel
+	context.Context
+	inner Context
+	noted *int32
+}
+
+func (c *cancelSuppressed) emit(m Diagnostic) {
+	if c.Err() == nil {
+		forward(c.inner, m)
+	} else {
+		c.suppress()
+	}
+}
+
+// suppress notes the first suppressed output, in c or any of its groups.
+func (c *cancelSuppressed) suppress() {
+	if atomic.CompareAndSwapInt32(c.noted, 0, 1) {
+		Print(c.inner, "output suppressed: context cancelled")
+	}
+}
+
+func (c *cancelSuppressed) Group(title string, fn func(Interface)) {
+	if h := thelper(c.inner); h != nil {
+		h()
+	}
+	if c.Err() != nil {
+		c.suppress()
+		fn(c)
+		return
+	}
+	GroupContext(c.inner, title, func(g Context) {
+		fn(suppressCancelled(g, c.noted))
+	})
+}
+
+func (c *cancelSuppressed) GroupContext(title string, fn func(Context)) {
+	if h := thelper(c.inner); h != nil {
+		h()
+	}
+	if c.Err() != nil {
+		c.suppress()
+		fn(c)
+		return
+	}
+	GroupContext(c.inner, title, func(g Context) {
+		fn(suppressCancelled(g, c.noted))
+	})
+}
@@ -0,0 +1,50 @@
+package diag_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/mutility/diag"
+)
+
+func TestWithCancelSuppression(t *testing.T) {
+	sb := &strings.Builder{}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	d := diag.WithCancelSuppression(diag.WithContext(ctx, diag.NewWriterDebug(sb)))
+	diag.GroupContext(d, "step", func(d diag.Context) {
+		diag.Print(d, "before")
+		diag.WarningAt(d, "a.go", 1, 2, "located before")
+		cancel()
+		diag.Debug(d, "after")
+		diag.Printf(d, "after %d", 1)
+		diag.WarningAt(d, "a.go", 3, 4, "after")
+		diag.ErrorAtf(d, "a.go", 5, 6, "after %d", 2)
+	})
+	diag.Error(d, "after group")
+
+	want := "step:\n" +
+		"  before\n" +
+		"[a.go:1.2]   located before\n" +
+		"  output suppressed: context cancelled\n"
+	if got := sb.String(); got != want {
+		t.Errorf("got %q; want %q", got, want)
+	}
+}
+
+func TestWithCancelSuppressionLateGroup(t *testing.T) {
+	sb := &strings.Builder{}
+	ctx, cancel := context.WithCancel(context.Background())
+	d := diag.WithCancelSuppression(diag.WithContext(ctx, diag.NewWriter(sb)))
+	cancel()
+	diag.Group(d, "late", func(d diag.Interface) {
+		diag.Print(d, "dropped")
+	})
+	diag.GroupContext(d, "late2", func(d diag.Context) {
+		diag.Print(d, "dropped")
+	})
+	if got, want := sb.String(), "output suppressed: context cancelled\n"; got != want {
+		t.Errorf("got %q; want %q", got, want)
+	}
+}
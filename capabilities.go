@@ -0,0 +1,55 @@
+package diag
+
+// capabilities lists the optional interfaces reported by Capabilities, in the
+// order they are declared.
+var capabilities = []struct {
+	name string
+	is   func(interface{}) bool
+}{
+	{"Debugger", func(d interface{}) bool { _, ok := d.(Debugger); return ok }},
+	{"Debugfer", func(d interface{}) bool { _, ok := d.(Debugfer); return ok }},
+	{"DebugAter", func(d interface{}) bool { _, ok := d.(DebugAter); return ok }},
+	{"DebugAtfer", func(d interface{}) bool { _, ok := d.(DebugAtfer); return ok }},
+	{"Printer", func(d interface{}) bool { _, ok := d.(Printer); return ok }},
+	{"Printfer", func(d interface{}) bool { _, ok := d.(Printfer); return ok }},
+	{"PrintAter", func(d interface{}) bool { _, ok := d.(PrintAter); return ok }},
+	{"PrintAtfer", func(d interface{}) bool { _, ok := d.(PrintAtfer); return ok }},
+	{"Errorer", func(d interface{}) bool { _, ok := d.(Errorer); return ok }},
+	{"Errorfer", func(d interface{}) bool { _, ok := d.(Errorfer); return ok }},
+	{"ErrorAter", func(d interface{}) bool { _, ok := d.(ErrorAter); return ok }},
+	{"ErrorAtfer", func(d interface{}) bool { _, ok := d.(ErrorAtfer); return ok }},
+	{"Warninger", func(d interface{}) bool { _, ok := d.(Warninger); return ok }},
+	{"Warningfer", func(d interface{}) bool { _, ok := d.(Warningfer); return ok }},
+	{"WarningAter", func(d interface{}) bool { _, ok := d.(WarningAter); return ok }},
+	{"WarningAtfer", func(d interface{}) bool { _, ok := d.(WarningAtfer); return ok }},
+	{"Infoer", func(d interface{}) bool { _, ok := d.(Infoer); return ok }},
+	{"Infofer", func(d interface{}) bool { _, ok := d.(Infofer); return ok }},
+	{"InfoAter", func(d interface{}) bool { _, ok := d.(InfoAter); return ok }},
+	{"InfoAtfer", func(d interface{}) bool { _, ok := d.(InfoAtfer); return ok }},
+	{"Grouper", func(d interface{}) bool { _, ok := d.(Grouper); return ok }},
+	{"GroupContexter", func(d interface{}) bool { _, ok := d.(GroupContexter); return ok }},
+	{"Fataler", func(d interface{}) bool { _, ok := d.(Fataler); return ok }},
+	{"ValueMasker", func(d interface{}) bool { _, ok := d.(ValueMasker); return ok }},
+	{"ValueUnmasker", func(d interface{}) bool { _, ok := d.(ValueUnmasker); return ok }},
+	{"MaskClearer", func(d interface{}) bool { _, ok := d.(MaskClearer); return ok }},
+	{"CorrelationIDer", func(d interface{}) bool { _, ok := d.(CorrelationIDer); return ok }},
+	{"AtFormatter", func(d interface{}) bool { _, ok := d.(AtFormatter); return ok }},
+	{"RangeErrorAter", func(d interface{}) bool { _, ok := d.(RangeErrorAter); return ok }},
+	{"RangeErrorAtfer", func(d interface{}) bool { _, ok := d.(RangeErrorAtfer); return ok }},
+	{"RangeWarningAter", func(d interface{}) bool { _, ok := d.(RangeWarningAter); return ok }},
+	{"RangeWarningAtfer", func(d interface{}) bool { _, ok := d.(RangeWarningAtfer); return ok }},
+	{"FieldsAttacher", func(d interface{}) bool { _, ok := d.(FieldsAttacher); return ok }},
+}
+
+// Capabilities returns the names of the interfaces declared by diag, such as
+// Debugfer, ErrorAter, or Grouper, that d implements. This helps diagnose why
+// diag falls back rather than using a method d was expected to provide.
+func Capabilities(d interface{}) []string {
+	var caps []string
+	for _, c := range capabilities {
+		if c.is(d) {
+			caps = append(caps, c.name)
+		}
+	}
+	return caps
+}
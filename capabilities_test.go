@@ -0,0 +1,36 @@
+package diag_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/mutility/diag"
+)
+
+func TestCapabilities(t *testing.T) {
+	var got string
+	type full struct{ diag.FullInterface }
+	for _, tt := range []struct {
+		name string
+		d    interface{}
+		want []string
+	}{
+		{"nil", nil, nil},
+		{"fill", &fill{}, []string{"Debugger", "Printer", "Errorer", "Warninger"}},
+		{"hasat", &hasat{&got}, []string{"Debugger", "DebugAter", "Printer", "PrintAter", "Errorer", "ErrorAter", "Warninger", "WarningAter"}},
+		{"full", full{}, []string{
+			"Debugger", "Debugfer", "DebugAter", "DebugAtfer",
+			"Printer", "Printfer", "PrintAter", "PrintAtfer",
+			"Errorer", "Errorfer", "ErrorAter", "ErrorAtfer",
+			"Warninger", "Warningfer", "WarningAter", "WarningAtfer",
+			"Infoer", "Infofer", "InfoAter", "InfoAtfer",
+			"Grouper", "ValueMasker",
+		}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := diag.Capabilities(tt.d); fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("got %v; want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,15 @@
+package diag
+
+// NewCase creates an Interface that applies transform, such as
+// strings.ToUpper, to each rendered message before forwarding it to inner.
+// Locations are forwarded unchanged.
+//
+// Messages are masked before transform is applied, so a transform that
+// alters the mask replacement will alter it in the output as well. The
+// default "***" is unaffected by case changes.
+func NewCase(inner Interface, transform func(string) string) Interface {
+	return &funnel{emit: func(m Diagnostic) {
+		m.Msg = transform(m.Msg)
+		forward(inner, m)
+	}}
+}
@@ -0,0 +1,31 @@
+package diag_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mutility/diag"
+)
+
+func TestCase(t *testing.T) {
+	for _, tt := range []struct {
+		name      string
+		transform func(string) string
+		want      string
+	}{
+		{"upper", strings.ToUpper, "MIXED CASE ***\n[Fn.go:1.2] LOCATED\n"},
+		{"lower", strings.ToLower, "mixed case ***\n[Fn.go:1.2] located\n"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			sb := &strings.Builder{}
+			d := diag.NewCase(diag.NewWriter(sb), tt.transform)
+			diag.MaskValue(d, "Secret")
+			t.Cleanup(func() { diag.Forget(d) })
+			diag.Warningf(d, "Mixed Case %s", "Secret")
+			diag.ErrorAt(d, "Fn.go", 1, 2, "Located")
+			if got := sb.String(); got != tt.want {
+				t.Errorf("got %q; want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package diag
+
+import (
+	"sync"
+	"time"
+)
+
+// Checkpoints records named points in time for reporting phase durations.
+// See NewCheckpoints.
+type Checkpoints struct {
+	d     Interface
+	now   func() time.Time
+	mu    sync.Mutex
+	marks []checkpoint
+}
+
+type checkpoint struct {
+	name string
+	at   time.Time
+}
+
+// NewCheckpoints creates a Checkpoints that reports to d. Times come from the
+// Clock passed to WithClock, if any, and otherwise from the system clock.
+func NewCheckpoints(d Interface, opts ...Option) *Checkpoints {
+	o := newOptions(opts)
+	return &Checkpoints{d: d, now: o.now}
+}
+
+// Mark records the current time under name.
+func (c *Checkpoints) Mark(name string) {
+	now := c.now()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.marks = append(c.marks, checkpoint{name, now})
+}
+
+// Since returns the time elapsed since the most recent mark named name, or
+// zero if there is no such mark.
+func (c *Checkpoints) Since(name string) time.Duration {
+	now := c.now()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i := len(c.marks) - 1; i >= 0; i-- {
+		if c.marks[i].name == name {
+			return now.Sub(c.marks[i].at)
+		}
+	}
+	return 0
+}
+
+// Report prints a line for each interval between consecutive marks, in the
+// order they were marked:
+//
+//     parse -> check: 1.5s
+//
+func (c *Checkpoints) Report() {
+	if h := thelper(c.d); h != nil {
+		h()
+	}
+	c.mu.Lock()
+	marks := append([]checkpoint(nil), c.marks...)
+	c.mu.Unlock()
+	for i := 1; i < len(marks); i++ {
+		Printf(c.d, "%s -> %s: %v", marks[i-1].name, marks[i].name, marks[i].at.Sub(marks[i-1].at))
+	}
+}
@@ -0,0 +1,34 @@
+package diag_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mutility/diag"
+)
+
+func TestCheckpoints(t *testing.T) {
+	clock := diag.NewFakeClock(time.Date(2021, 3, 4, 5, 6, 7, 0, time.UTC))
+	sb := &strings.Builder{}
+	c := diag.NewCheckpoints(diag.NewWriter(sb), diag.WithClock(clock))
+	c.Mark("start")
+	clock.Advance(1500 * time.Millisecond)
+	c.Mark("parse")
+	clock.Advance(250 * time.Millisecond)
+	c.Mark("check")
+	clock.Advance(2 * time.Second)
+
+	if got, want := c.Since("parse"), 2250*time.Millisecond; got != want {
+		t.Errorf("Since(parse): got %v; want %v", got, want)
+	}
+	if got := c.Since("missing"); got != 0 {
+		t.Errorf("Since(missing): got %v; want 0", got)
+	}
+
+	c.Report()
+	want := "start -> parse: 1.5s\nparse -> check: 250ms\n"
+	if got := sb.String(); got != want {
+		t.Errorf("got %q; want %q", got, want)
+	}
+}
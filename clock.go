@@ -0,0 +1,45 @@
+package diag
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock supplies the current time to diag's time-based features. Constructors
+// that use it accept WithClock, and otherwise use the system clock.
+type Clock interface {
+	Now() time.Time
+}
+
+// FakeClock is a Clock for tests. Its time changes only when Set or Advance
+// is called.
+type FakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+// NewFakeClock creates a FakeClock reporting now.
+func NewFakeClock(now time.Time) *FakeClock {
+	return &FakeClock{now: now}
+}
+
+// Now returns the fake current time.
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// Set changes the fake current time to now.
+func (c *FakeClock) Set(now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = now
+}
+
+// Advance moves the fake current time forward by d.
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
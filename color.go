@@ -0,0 +1,79 @@
+package diag
+
+import (
+	"io"
+	"os"
+	"sync"
+)
+
+// Colors holds the SGR parameters, such as "31" for red or "1;33" for bold
+// yellow, that NewColor uses for messages at each level. Levels with an empty
+// value are not colored.
+type Colors struct {
+	Debug, Info, Print, Warning, Error string
+}
+
+// DefaultColors are the colors used by NewColor unless WithColors is
+// supplied: faint debug messages, cyan info messages, yellow warnings, and
+// red errors.
+var DefaultColors = Colors{Debug: "2", Info: "36", Warning: "33", Error: "31"}
+
+// NewColor creates an Interface that writes each message to w as a line,
+// colored according to its level with ANSI escape codes. Located messages
+// are preceded by their location as formatted by FormatAt, and the codes
+// surround the whole line, so that editors can still parse the location.
+//
+// Colors are only used when w is a terminal and the NO_COLOR environment
+// variable is empty; pass WithTerminal to force them on or off.
+//
+// Write errors are passed to the handler supplied by WithErrorHandler.
+func NewColor(w io.Writer, opts ...Option) Interface {
+	o := newOptions(opts)
+	c := &colorWriter{w: w, opts: o, colors: DefaultColors}
+	if o.colors != nil {
+		c.colors = *o.colors
+	}
+	if o.terminal != nil {
+		c.enabled = *o.terminal
+	} else {
+		c.enabled = os.Getenv("NO_COLOR") == "" && o.isTerminal(w)
+	}
+	return &funnel{emit: c.emit}
+}
+
+type colorWriter struct {
+	mu      sync.Mutex
+	w       io.Writer
+	opts    options
+	colors  Colors
+	enabled bool
+}
+
+func (c *colorWriter) emit(m Diagnostic) {
+	line := m.Msg
+	if loc := FormatAt(m.File, m.Line, m.Col); loc != "" {
+		line = loc + " " + line
+	}
+	if sgr := c.sgr(m.Level); c.enabled && sgr != "" {
+		line = "\x1b[" + sgr + "m" + line + "\x1b[0m"
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_, err := io.WriteString(c.w, line+"\n")
+	c.opts.error(err)
+}
+
+func (c *colorWriter) sgr(l Level) string {
+	switch l {
+	case LevelDebug:
+		return c.colors.Debug
+	case LevelInfo:
+		return c.colors.Info
+	case LevelPrint:
+		return c.colors.Print
+	case LevelWarning:
+		return c.colors.Warning
+	}
+	return c.colors.Error
+}
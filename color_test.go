@@ -0,0 +1,38 @@
+package diag_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mutility/diag"
+)
+
+func TestColor(t *testing.T) {
+	emit := func(d diag.Interface) {
+		diag.Debug(d, "d")
+		diag.Info(d, "i")
+		diag.Print(d, "p")
+		diag.Warningf(d, "w %d", 1)
+		diag.ErrorAt(d, "fn.go", 10, 3, "e")
+	}
+	for _, tt := range []struct {
+		name string
+		opts []diag.Option
+		want string
+	}{
+		{"detected", nil, "d\ni\np\nw 1\n[fn.go:10.3] e\n"},
+		{"off", []diag.Option{diag.WithTerminal(false)}, "d\ni\np\nw 1\n[fn.go:10.3] e\n"},
+		{"on", []diag.Option{diag.WithTerminal(true)},
+			"\x1b[2md\x1b[0m\n\x1b[36mi\x1b[0m\np\n\x1b[33mw 1\x1b[0m\n\x1b[31m[fn.go:10.3] e\x1b[0m\n"},
+		{"custom", []diag.Option{diag.WithTerminal(true), diag.WithColors(diag.Colors{Print: "1", Error: "1;35"})},
+			"d\ni\n\x1b[1mp\x1b[0m\nw 1\n\x1b[1;35m[fn.go:10.3] e\x1b[0m\n"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			sb := &strings.Builder{}
+			emit(diag.NewColor(sb, tt.opts...))
+			if got := sb.String(); got != tt.want {
+				t.Errorf("got %q; want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,20 @@
+package diag
+
+// WithCorrelationID returns an Interface that prefixes every message issued
+// through it with "[id] " before forwarding it to d, to tie together the
+// diagnostics of a single request. Masks registered on d or on the returned
+// Interface apply to id as well.
+//
+// If d implements CorrelationIDer, it owns the implementation, so that
+// structured sinks can record id as a field instead.
+func WithCorrelationID(d Interface, id string) Interface {
+	if c, ok := d.(CorrelationIDer); ok {
+		return c.WithCorrelationID(id)
+	}
+	f := &funnel{}
+	f.emit = func(m Diagnostic) {
+		m.Msg = "[" + mask(f).replace(id) + "] " + m.Msg
+		forward(d, m)
+	}
+	return f
+}
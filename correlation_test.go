@@ -0,0 +1,54 @@
+package diag_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mutility/diag"
+)
+
+func TestWithCorrelationID(t *testing.T) {
+	sb := &strings.Builder{}
+	inner := diag.NewWriterDebug(sb)
+	d := diag.WithCorrelationID(inner, "req-42")
+	diag.Debug(d, "debug")
+	diag.Printf(d, "print %d", 1)
+	diag.Warning(d, "warning")
+	diag.ErrorAt(d, "fn.go", 1, 2, "error")
+
+	want := "[req-42] debug\n[req-42] print 1\n[req-42] warning\n[fn.go:1.2] [req-42] error\n"
+	if got := sb.String(); got != want {
+		t.Errorf("got %q; want %q", got, want)
+	}
+
+	sb.Reset()
+	masked := diag.WithCorrelationID(inner, "secret-id")
+	diag.MaskValue(masked, "secret")
+	t.Cleanup(func() { diag.Forget(masked) })
+	diag.Print(masked, "masked")
+	if got, want := sb.String(), "[***-id] masked\n"; got != want {
+		t.Errorf("got %q; want %q", got, want)
+	}
+}
+
+// structured records a correlation id as a field.
+type structured struct {
+	fill
+	id string
+}
+
+func (s *structured) WithCorrelationID(id string) diag.Interface {
+	return &structured{id: id}
+}
+
+func TestWithCorrelationIDOwned(t *testing.T) {
+	d := diag.WithCorrelationID(&structured{}, "req-42")
+	s, ok := d.(*structured)
+	if !ok {
+		t.Fatalf("got %T; want *structured", d)
+	}
+	diag.Print(d, "message")
+	if s.id != "req-42" || s.print() != "message\n" {
+		t.Errorf("got id %q; want %q with unprefixed message", s.id, "req-42")
+	}
+}
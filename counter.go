@@ -0,0 +1,109 @@
+package diag
+
+import (
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+// Counter is an Interface that counts the messages it forwards at each
+// level. See NewCounter.
+type Counter struct {
+	funnel
+
+	inner  Interface
+	counts [LevelError + 1]int64
+}
+
+// NewCounter creates a Counter that forwards to inner, counting each message
+// by its level. This suits build tools that exit with a nonzero status if
+// any errors were reported. Counts are updated atomically, so it may be used
+// concurrently.
+func NewCounter(inner Interface) *Counter {
+	c := &Counter{inner: inner}
+	c.funnel.emit = c.count
+	return c
+}
+
+func (c *Counter) count(m Diagnostic) {
+	if m.Level >= 0 && int(m.Level) < len(c.counts) {
+		atomic.AddInt64(&c.counts[m.Level], 1)
+	}
+	forward(c.inner, m)
+}
+
+func (c *Counter) load(l Level) int {
+	return int(atomic.LoadInt64(&c.counts[l]))
+}
+
+// Debugs returns the number of debug messages forwarded so far.
+func (c *Counter) Debugs() int { return c.load(LevelDebug) }
+
+// Infos returns the number of info messages forwarded so far.
+func (c *Counter) Infos() int { return c.load(LevelInfo) }
+
+// Prints returns the number of print messages forwarded so far.
+func (c *Counter) Prints() int { return c.load(LevelPrint) }
+
+// Warnings returns the number of warnings forwarded so far.
+func (c *Counter) Warnings() int { return c.load(LevelWarning) }
+
+// Errors returns the number of errors forwarded so far.
+func (c *Counter) Errors() int { return c.load(LevelError) }
+
+// ExitCode returns 1 if c counted any errors, and 0 otherwise, for passing
+// to os.Exit at the end of a run.
+func ExitCode(c *Counter) int {
+	if c.Errors() > 0 {
+		return 1
+	}
+	return 0
+}
+
+// ExitCodeWarn is like ExitCode, but also returns 1 if c counted any
+// warnings, as when warnings are treated as errors.
+func ExitCodeWarn(c *Counter) int {
+	if c.Errors() > 0 || c.Warnings() > 0 {
+		return 1
+	}
+	return 0
+}
+
+// Summary prints a line totalling the errors and warnings counted by c to d,
+// such as "Done: 1 error, 5 warnings", omitting either count if it is zero.
+// If any errors were counted, the line is issued as an error instead, so that
+// it remains visible when less severe messages are filtered. Pass d rather
+// than c itself, so the summary is not counted.
+func Summary(c *Counter, d Interface) {
+	SummaryLevel(c, d, LevelError)
+}
+
+// SummaryLevel is like Summary, but issues the line at onErrors, such as
+// LevelWarning or LevelPrint, if any errors were counted.
+func SummaryLevel(c *Counter, d Interface, onErrors Level) {
+	if h := thelper(d); h != nil {
+		h()
+	}
+	var counts []string
+	if n := c.Errors(); n > 0 {
+		counts = append(counts, plural(n, "error"))
+	}
+	if n := c.Warnings(); n > 0 {
+		counts = append(counts, plural(n, "warning"))
+	}
+	m := Diagnostic{Level: LevelPrint, Msg: "Done: no errors or warnings"}
+	if len(counts) > 0 {
+		m.Msg = "Done: " + strings.Join(counts, ", ")
+	}
+	if c.Errors() > 0 {
+		m.Level = onErrors
+	}
+	forward(d, m)
+}
+
+func plural(n int, noun string) string {
+	if n == 1 {
+		return "1 " + noun
+	}
+	return strconv.Itoa(n) + " " + noun + "s"
+}
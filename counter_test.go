@@ -0,0 +1,110 @@
+package diag_test
+
+import (
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/mutility/diag"
+)
+
+func TestCounter(t *testing.T) {
+	c := diag.NewCounter(diag.NewWriterDebug(io.Discard))
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			diag.Debug(c, "d")
+			diag.Debugf(c, "d")
+			diag.DebugAt(c, "fn.go", 1, 2, "d")
+			diag.DebugAtf(c, "fn.go", 1, 2, "d")
+			diag.Info(c, "i")
+			diag.InfoAtf(c, "fn.go", 1, 2, "i")
+			diag.Print(c, "p")
+			diag.Printf(c, "p")
+			diag.PrintAt(c, "fn.go", 1, 2, "p")
+			diag.Warning(c, "w")
+			diag.Warningf(c, "w")
+			diag.WarningAt(c, "fn.go", 1, 2, "w")
+			diag.WarningAtf(c, "fn.go", 1, 2, "w")
+			diag.Error(c, "e")
+			diag.Errorf(c, "e")
+			diag.ErrorAt(c, "fn.go", 1, 2, "e")
+			diag.ErrorAtf(c, "fn.go", 1, 2, "e")
+		}()
+	}
+	wg.Wait()
+	for name, tt := range map[string]struct{ got, want int }{
+		"Debugs":   {c.Debugs(), 40},
+		"Infos":    {c.Infos(), 20},
+		"Prints":   {c.Prints(), 30},
+		"Warnings": {c.Warnings(), 40},
+		"Errors":   {c.Errors(), 40},
+	} {
+		if tt.got != tt.want {
+			t.Errorf("%s: got %d; want %d", name, tt.got, tt.want)
+		}
+	}
+}
+
+func TestSummary(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		warnings int
+		errors   int
+		onErrors diag.Level
+		want     string
+	}{
+		{"clean", 0, 0, diag.LevelError, "Done: no errors or warnings\n"},
+		{"singular", 1, 1, diag.LevelError, "error: Done: 1 error, 1 warning\n"},
+		{"plural", 5, 2, diag.LevelWarning, "warning: Done: 2 errors, 5 warnings\n"},
+		{"warnings", 2, 0, diag.LevelError, "Done: 2 warnings\n"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			c := diag.NewCounter(diag.Discard)
+			for i := 0; i < tt.warnings; i++ {
+				diag.Warning(c, "w")
+			}
+			for i := 0; i < tt.errors; i++ {
+				diag.Error(c, "e")
+			}
+			sb := &strings.Builder{}
+			d := diag.NewWriters5(diag.NewPrefixed(sb, "error:"), diag.NewPrefixed(sb, "warning:"), sb, sb, sb)
+			diag.SummaryLevel(c, d, tt.onErrors)
+			if got := sb.String(); got != tt.want {
+				t.Errorf("got %q; want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExitCode(t *testing.T) {
+	for _, tt := range []struct {
+		name             string
+		warn, err        bool
+		exitCode, orWarn int
+	}{
+		{"clean", false, false, 0, 0},
+		{"warning", true, false, 0, 1},
+		{"error", false, true, 1, 1},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			c := diag.NewCounter(diag.Discard)
+			diag.Print(c, "p")
+			if tt.warn {
+				diag.Warning(c, "w")
+			}
+			if tt.err {
+				diag.ErrorAt(c, "fn.go", 1, 2, "e")
+			}
+			if got := diag.ExitCode(c); got != tt.exitCode {
+				t.Errorf("ExitCode: got %d; want %d", got, tt.exitCode)
+			}
+			if got := diag.ExitCodeWarn(c); got != tt.orWarn {
+				t.Errorf("ExitCodeWarn: got %d; want %d", got, tt.orWarn)
+			}
+		})
+	}
+}
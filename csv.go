@@ -0,0 +1,57 @@
+package diag
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// NewCSV creates an Interface that writes each message to w as a CSV row
+// with the columns level, file, line, col, and message, preceded by a header
+// row. File, line, and col are empty for messages without a location. If
+// WithTimeColumn is supplied, a leading time column holds each message's time
+// in RFC 3339 format.
+//
+// Write errors are passed to the handler supplied by WithErrorHandler.
+func NewCSV(w io.Writer, opts ...Option) Interface {
+	c := &csvWriter{w: csv.NewWriter(w), opts: newOptions(opts)}
+	return &funnel{emit: c.emit}
+}
+
+type csvWriter struct {
+	mu     sync.Mutex
+	w      *csv.Writer
+	opts   options
+	header bool
+}
+
+func (c *csvWriter) emit(m Diagnostic) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	timed := c.opts.timeColumn
+	if !c.header {
+		c.header = true
+		header := []string{"level", "file", "line", "col", "message"}
+		if timed {
+			header = append([]string{"time"}, header...)
+		}
+		c.w.Write(header)
+	}
+
+	var line, col string
+	if m.Line != 0 {
+		line = strconv.Itoa(m.Line)
+	}
+	if m.Col != 0 {
+		col = strconv.Itoa(m.Col)
+	}
+	row := []string{m.Level.String(), m.File, line, col, m.Msg}
+	if timed {
+		row = append([]string{c.opts.now().Format(time.RFC3339)}, row...)
+	}
+	c.w.Write(row)
+	c.w.Flush()
+	c.opts.error(c.w.Error())
+}
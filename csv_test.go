@@ -0,0 +1,62 @@
+package diag_test
+
+import (
+	"encoding/csv"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mutility/diag"
+)
+
+func TestCSV(t *testing.T) {
+	sb := &strings.Builder{}
+	d := diag.NewCSV(sb)
+	diag.MaskValue(d, "secret")
+	t.Cleanup(func() { diag.Forget(d) })
+	diag.Debug(d, "plain")
+	diag.Printf(d, "a, b, and %q", "c")
+	diag.WarningAt(d, "fn.go", 10, 0, "two\nlines")
+	diag.ErrorAtf(d, "fn.go", 10, 3, "%s", "secret")
+
+	rows, err := csv.NewReader(strings.NewReader(sb.String())).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]string{
+		{"level", "file", "line", "col", "message"},
+		{"debug", "", "", "", "plain"},
+		{"print", "", "", "", `a, b, and "c"`},
+		{"warning", "fn.go", "10", "", "two\nlines"},
+		{"error", "fn.go", "10", "3", "***"},
+	}
+	if fmt.Sprintf("%q", rows) != fmt.Sprintf("%q", want) {
+		t.Errorf("got %q; want %q", rows, want)
+	}
+}
+
+func TestCSVTime(t *testing.T) {
+	clock := diag.NewFakeClock(time.Date(2021, 3, 4, 5, 6, 7, 0, time.UTC))
+	sb := &strings.Builder{}
+	d := diag.NewCSV(sb, diag.WithTimeColumn(), diag.WithClock(clock))
+	diag.Warning(d, "first")
+	clock.Advance(time.Second)
+	diag.Error(d, "second")
+
+	want := "time,level,file,line,col,message\n" +
+		"2021-03-04T05:06:07Z,warning,,,,first\n" +
+		"2021-03-04T05:06:08Z,error,,,,second\n"
+	if got := sb.String(); got != want {
+		t.Errorf("got %q; want %q", got, want)
+	}
+}
+
+func TestCSVClockOnly(t *testing.T) {
+	sb := &strings.Builder{}
+	d := diag.NewCSV(sb, diag.WithClock(diag.NewFakeClock(time.Now())))
+	diag.Print(d, "plain")
+	if got, want := sb.String(), "level,file,line,col,message\nprint,,,,plain\n"; got != want {
+		t.Errorf("got %q; want %q", got, want)
+	}
+}
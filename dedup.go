@@ -0,0 +1,66 @@
+package diag
+
+import (
+	"fmt"
+	"sync"
+)
+
+// KeyedDedup is an Interface that suppresses diagnostics whose key has been
+// seen before. See NewKeyedDedup.
+type KeyedDedup struct {
+	funnel
+
+	inner Interface
+	key   func(level Level, file string, line, col int, msg string) string
+
+	mu   sync.Mutex
+	seen map[string]struct{}
+}
+
+// NewKeyedDedup creates an Interface that forwards to inner only the first
+// diagnostic for each key computed by key. This allows messages that differ
+// textually, such as by an embedded timestamp, to be treated as duplicates.
+// The message passed to key has already been masked.
+func NewKeyedDedup(inner Interface, key func(level Level, file string, line, col int, msg string) string) *KeyedDedup {
+	kd := &KeyedDedup{inner: inner, key: key, seen: make(map[string]struct{})}
+	kd.funnel.emit = kd.dedup
+	return kd
+}
+
+func (kd *KeyedDedup) dedup(m Diagnostic) {
+	k := kd.key(m.Level, m.File, m.Line, m.Col, m.Msg)
+	kd.mu.Lock()
+	_, dup := kd.seen[k]
+	kd.seen[k] = struct{}{}
+	kd.mu.Unlock()
+	if !dup {
+		forward(kd.inner, m)
+	}
+}
+
+// Reset forgets all keys seen so far.
+func (kd *KeyedDedup) Reset() {
+	kd.mu.Lock()
+	defer kd.mu.Unlock()
+	kd.seen = make(map[string]struct{})
+}
+
+// Dedup creates an Interface that forwards to inner only the first of
+// identical diagnostics: those with the same level, location, and message.
+// Messages are compared after masking, both by masks registered on the
+// result and by those registered on inner, so messages that differ only in
+// masked values are duplicates. Call DedupReset to forget the diagnostics
+// seen so far, such as between passes.
+func Dedup(inner Interface) Interface {
+	return NewKeyedDedup(inner, func(level Level, file string, line, col int, msg string) string {
+		return fmt.Sprintf("%d\x00%s\x00%d\x00%d\x00%s", level, file, line, col, mask(inner).replace(msg))
+	})
+}
+
+// DedupReset forgets the diagnostics seen so far by d, which should have been
+// created by Dedup or NewKeyedDedup. It does nothing for other Interfaces.
+func DedupReset(d Interface) {
+	if kd, ok := d.(*KeyedDedup); ok {
+		kd.Reset()
+	}
+}
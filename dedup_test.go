@@ -0,0 +1,49 @@
+package diag_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mutility/diag"
+)
+
+func TestKeyedDedup(t *testing.T) {
+	sb := &strings.Builder{}
+	// key on the message up to the timestamp
+	d := diag.NewKeyedDedup(diag.NewWriter(sb), func(level diag.Level, file string, line, col int, msg string) string {
+		return level.String() + ":" + strings.SplitN(msg, " at ", 2)[0]
+	})
+	diag.Warning(d, "disk full at 10:00:01")
+	diag.Warning(d, "disk full at 10:00:02")
+	diag.Error(d, "disk full at 10:00:03") // a different level
+	diag.Print(d, "retrying at 10:00:04")
+	d.Reset()
+	diag.Warning(d, "disk full at 10:00:05")
+
+	want := "disk full at 10:00:01\ndisk full at 10:00:03\nretrying at 10:00:04\ndisk full at 10:00:05\n"
+	if got := sb.String(); got != want {
+		t.Errorf("got %q; want %q", got, want)
+	}
+}
+
+func TestDedup(t *testing.T) {
+	sb := &strings.Builder{}
+	w := diag.NewWriter(sb)
+	diag.MaskValue(w, "hunter2")
+	diag.MaskValue(w, "swordfish")
+	d := diag.Dedup(w)
+	diag.ErrorAt(d, "a.go", 1, 2, "bad")
+	diag.ErrorAtf(d, "a.go", 1, 2, "%s", "bad")
+	diag.ErrorAt(d, "a.go", 1, 3, "bad")   // a different location
+	diag.WarningAt(d, "a.go", 1, 2, "bad") // a different level
+	diag.Error(d, "password hunter2")
+	diag.Error(d, "password swordfish") // identical once masked
+	diag.DedupReset(d)
+	diag.ErrorAt(d, "a.go", 1, 2, "bad")
+	diag.Forget(w)
+
+	want := "[a.go:1.2] bad\n[a.go:1.3] bad\n[a.go:1.2] bad\npassword ***\n[a.go:1.2] bad\n"
+	if got := sb.String(); got != want {
+		t.Errorf("got %q; want %q", got, want)
+	}
+}
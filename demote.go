@@ -0,0 +1,24 @@
+package diag
+
+import "sync"
+
+// NewDemote creates an Interface that forwards to inner, except that once a
+// warning has been emitted afterCount times, further identical warnings are
+// emitted as debug messages instead. Warnings are identical if they have the
+// same message and location. This quiets noisy warnings while keeping a
+// record of them.
+func NewDemote(inner Interface, afterCount int) Interface {
+	var mu sync.Mutex
+	seen := make(map[Diagnostic]int)
+	return &funnel{emit: func(m Diagnostic) {
+		if m.Level == LevelWarning {
+			mu.Lock()
+			seen[m]++
+			if seen[m] > afterCount {
+				m.Level = LevelDebug
+			}
+			mu.Unlock()
+		}
+		forward(inner, m)
+	}}
+}
@@ -0,0 +1,30 @@
+package diag_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mutility/diag"
+)
+
+func TestDemote(t *testing.T) {
+	w, dbg := &strings.Builder{}, &strings.Builder{}
+	d := diag.NewDemote(diag.NewWriters(w, w, dbg), 2)
+	for i := 0; i < 4; i++ {
+		diag.WarningAt(d, "fn.go", 1, 2, "noisy")
+		diag.Warning(d, "noisy") // a different location
+	}
+	diag.WarningAt(d, "fn.go", 3, 4, "noisy")
+	diag.Error(d, "noisy") // errors are never demoted
+	diag.Error(d, "noisy")
+	diag.Error(d, "noisy")
+
+	wantWarn := "[fn.go:1.2] noisy\nnoisy\n[fn.go:1.2] noisy\nnoisy\n[fn.go:3.4] noisy\nnoisy\nnoisy\nnoisy\n"
+	if got := w.String(); got != wantWarn {
+		t.Errorf("warnings: got %q; want %q", got, wantWarn)
+	}
+	wantDebug := "[fn.go:1.2] noisy\nnoisy\n[fn.go:1.2] noisy\nnoisy\n"
+	if got := dbg.String(); got != wantDebug {
+		t.Errorf("debug: got %q; want %q", got, wantDebug)
+	}
+}
@@ -19,15 +19,32 @@ package diag
 import (
 	"context"
 	"fmt"
+	"os"
+	"reflect"
+	"regexp"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 )
 
 type (
 	Debugger  interface{ Debug(...interface{}) }
 	Debugfer  interface{ Debugf(string, ...interface{}) }
+	DebugAter interface {
+		DebugAt(string, int, int, ...interface{})
+	}
+	DebugAtfer interface {
+		DebugAtf(string, int, int, string, ...interface{})
+	}
 	Printer   interface{ Print(...interface{}) }
 	Printfer  interface{ Printf(string, ...interface{}) }
+	PrintAter interface {
+		PrintAt(string, int, int, ...interface{})
+	}
+	PrintAtfer interface {
+		PrintAtf(string, int, int, string, ...interface{})
+	}
 	Errorer   interface{ Error(...interface{}) }
 	Errorfer  interface{ Errorf(string, ...interface{}) }
 	ErrorAter interface {
@@ -44,13 +61,45 @@ type (
 	WarningAtfer interface {
 		WarningAtf(string, int, int, string, ...interface{})
 	}
+	Infoer   interface{ Info(...interface{}) }
+	Infofer  interface{ Infof(string, ...interface{}) }
+	InfoAter interface {
+		InfoAt(string, int, int, ...interface{})
+	}
+	InfoAtfer interface {
+		InfoAtf(string, int, int, string, ...interface{})
+	}
 	Grouper interface {
 		Group(string, func(Interface))
 	}
 	GroupContexter interface {
 		GroupContext(string, func(Context))
 	}
-	ValueMasker interface{ MaskValue(string) }
+	Fataler         interface{ Fatal(...interface{}) }
+	ValueMasker     interface{ MaskValue(string) }
+	ValueUnmasker   interface{ UnmaskValue(string) }
+	MaskClearer     interface{ ClearMasks() }
+	CorrelationIDer interface {
+		WithCorrelationID(string) Interface
+	}
+	AtFormatter interface {
+		FormatAt(string, int, int) string
+	}
+	RangeErrorAter interface {
+		ErrorAtRange(string, int, int, int, int, ...interface{})
+	}
+	RangeErrorAtfer interface {
+		ErrorAtRangef(string, int, int, int, int, string, ...interface{})
+	}
+	RangeWarningAter interface {
+		WarningAtRange(string, int, int, int, int, ...interface{})
+	}
+	RangeWarningAtfer interface {
+		WarningAtRangef(string, int, int, int, int, string, ...interface{})
+	}
+	FieldsAttacher interface {
+		WithFields(...interface{}) Interface
+	}
 )
 
 // Interface includes the core diagnostic methods. All functions in diag
@@ -88,12 +137,20 @@ type Context interface {
 type FullInterface interface {
 	Interface
 	Debugfer
+	DebugAter  // added:1.3
+	DebugAtfer // added:1.3
 	Errorfer
 	ErrorAter
 	ErrorAtfer
-	Grouper  // added:1.2
-	Printer  // added:1.1
-	Printfer // added:1.1
+	Grouper    // added:1.2
+	Infoer     // added:1.3
+	Infofer    // added:1.3
+	InfoAter   // added:1.3
+	InfoAtfer  // added:1.3
+	Printer    // added:1.1
+	Printfer   // added:1.1
+	PrintAter  // added:1.3
+	PrintAtfer // added:1.3
 	Warningfer
 	WarningAter
 	WarningAtfer
@@ -110,35 +167,85 @@ type wrapContext struct {
 	Interface
 }
 
-// Debug outputs a debug message, unless d is nil.
+// Debug outputs a debug message, unless d is nil or holds a nil pointer.
 func Debug(d Debugger, a ...interface{}) {
-	if d != nil {
-		if h := thelper(d); h != nil {
-			h()
-		}
-		m := mask(d)
-		d.Debug(m.Args(a)...)
+	if isNil(d) {
+		return
+	}
+	if h := thelper(d); h != nil {
+		h()
 	}
+	m := mask(d)
+	d.Debug(m.Args(a)...)
 }
 
-// Debugf outputs a formatted debug message, unless d is nil.
+// Debugf outputs a formatted debug message, unless d is nil or holds a nil
+// pointer.
 func Debugf(d Debugger, format string, a ...interface{}) {
+	if isNil(d) {
+		return
+	}
 	if h := thelper(d); h != nil {
 		h()
 	}
 	if df, ok := d.(Debugfer); ok {
 		m := mask(d)
 		df.Debugf(m.Format(format), m.Args(a)...)
-	} else if d != nil {
+	} else {
 		m := mask(d)
 		d.Debug(fmt.Sprintf(m.Format(format), m.Args(a)...))
 	}
 }
 
-// Print outputs a message, unless p is nil.
+// DebugAt outputs a debug message with location, unless d is nil or holds a
+// nil pointer.
+func DebugAt(d Debugger, file string, line, col int, a ...interface{}) {
+	if isNil(d) {
+		return
+	}
+	if h := thelper(d); h != nil {
+		h()
+	}
+	if da, ok := d.(DebugAter); ok {
+		da.DebugAt(file, line, col, mask(d).Args(a)...)
+	} else if df, ok := d.(DebugAtfer); ok {
+		df.DebugAtf(file, line, col, "%s", fmt.Sprint(mask(d).Args(a)...))
+	} else {
+		d.Debug(fillAt(d, file, line, col, mask(d).Args(a))...)
+	}
+}
+
+// DebugAtf outputs a formatted debug message with location, unless d is nil
+// or holds a nil pointer.
+func DebugAtf(d Debugger, file string, line, col int, format string, a ...interface{}) {
+	if isNil(d) {
+		return
+	}
+	if h := thelper(d); h != nil {
+		h()
+	}
+	if daf, ok := d.(DebugAtfer); ok {
+		m := mask(d)
+		daf.DebugAtf(file, line, col, m.Format(format), m.Args(a)...)
+	} else if da, ok := d.(DebugAter); ok {
+		m := mask(d)
+		da.DebugAt(file, line, col, fmt.Sprintf(m.Format(format), m.Args(a)...))
+	} else if df, ok := d.(Debugfer); ok {
+		m := mask(d)
+		df.Debugf(fillAtf(df, file, line, col, m.Format(format)), m.Args(a)...)
+	} else {
+		m := mask(d)
+		d.Debug(fmt.Sprintf(fillAtf(d, file, line, col, m.Format(format)), m.Args(a)...))
+	}
+}
+
+// Print outputs a message, unless p is nil or holds a nil pointer.
 //
 // "Ideally" p would be a Printer instead of an Interface, but it was added late.
 func Print(p Interface, a ...interface{}) {
+	if isNil(p) {
+		return
+	}
 	if p, ok := p.(Printer); ok {
 		if h := thelper(p); h != nil {
 			h()
@@ -147,10 +254,13 @@ func Print(p Interface, a ...interface{}) {
 	}
 }
 
-// Printf outputs a formatted message, unless p is nil.
+// Printf outputs a formatted message, unless p is nil or holds a nil pointer.
 //
 // "Ideally" p would be a Printer instead of an Interface, but it was added late.
 func Printf(p Interface, format string, a ...interface{}) {
+	if isNil(p) {
+		return
+	}
 	if h := thelper(p); h != nil {
 		h()
 	}
@@ -163,32 +273,87 @@ func Printf(p Interface, format string, a ...interface{}) {
 	}
 }
 
-// Error outputs an error message, unless e is nil.
+// PrintAt outputs a message with location, unless p is nil or holds a nil
+// pointer.
+//
+// Like Print, p is an Interface rather than a Printer.
+func PrintAt(p Interface, file string, line, col int, a ...interface{}) {
+	if isNil(p) {
+		return
+	}
+	if h := thelper(p); h != nil {
+		h()
+	}
+	if pa, ok := p.(PrintAter); ok {
+		pa.PrintAt(file, line, col, mask(p).Args(a)...)
+	} else if pf, ok := p.(PrintAtfer); ok {
+		pf.PrintAtf(file, line, col, "%s", fmt.Sprint(mask(p).Args(a)...))
+	} else if p, ok := p.(Printer); ok {
+		p.Print(fillAt(p, file, line, col, mask(p).Args(a))...)
+	}
+}
+
+// PrintAtf outputs a formatted message with location, unless p is nil or
+// holds a nil pointer.
+//
+// Like Print, p is an Interface rather than a Printer.
+func PrintAtf(p Interface, file string, line, col int, format string, a ...interface{}) {
+	if isNil(p) {
+		return
+	}
+	if h := thelper(p); h != nil {
+		h()
+	}
+	if paf, ok := p.(PrintAtfer); ok {
+		m := mask(p)
+		paf.PrintAtf(file, line, col, m.Format(format), m.Args(a)...)
+	} else if pa, ok := p.(PrintAter); ok {
+		m := mask(p)
+		pa.PrintAt(file, line, col, fmt.Sprintf(m.Format(format), m.Args(a)...))
+	} else if pf, ok := p.(Printfer); ok {
+		m := mask(p)
+		pf.Printf(fillAtf(pf, file, line, col, m.Format(format)), m.Args(a)...)
+	} else if p, ok := p.(Printer); ok {
+		m := mask(p)
+		p.Print(fmt.Sprintf(fillAtf(p, file, line, col, m.Format(format)), m.Args(a)...))
+	}
+}
+
+// Error outputs an error message, unless e is nil or holds a nil pointer.
 func Error(e Errorer, a ...interface{}) {
-	if e != nil {
-		if h := thelper(e); h != nil {
-			h()
-		}
-		e.Error(mask(e).Args(a)...)
+	if isNil(e) {
+		return
 	}
+	if h := thelper(e); h != nil {
+		h()
+	}
+	e.Error(mask(e).Args(a)...)
 }
 
-// Errorf outputs a formatted error message, unless e is nil.
+// Errorf outputs a formatted error message, unless e is nil or holds a nil
+// pointer.
 func Errorf(e Errorer, format string, a ...interface{}) {
+	if isNil(e) {
+		return
+	}
 	if h := thelper(e); h != nil {
 		h()
 	}
 	if ef, ok := e.(Errorfer); ok {
 		m := mask(e)
 		ef.Errorf(m.Format(format), m.Args(a)...)
-	} else if e != nil {
+	} else {
 		m := mask(e)
 		e.Error(fmt.Sprintf(m.Format(format), m.Args(a)...))
 	}
 }
 
-// ErrorAt outputs an error message with location, unless e is nil.
+// ErrorAt outputs an error message with location, unless e is nil or holds a
+// nil pointer.
 func ErrorAt(e Errorer, file string, line, col int, a ...interface{}) {
+	if isNil(e) {
+		return
+	}
 	if h := thelper(e); h != nil {
 		h()
 	}
@@ -196,13 +361,17 @@ func ErrorAt(e Errorer, file string, line, col int, a ...interface{}) {
 		ea.ErrorAt(file, line, col, mask(e).Args(a)...)
 	} else if ef, ok := e.(ErrorAtfer); ok {
 		ef.ErrorAtf(file, line, col, "%s", fmt.Sprint(mask(e).Args(a)...))
-	} else if e != nil {
-		e.Error(fillAt(file, line, col, mask(e).Args(a))...)
+	} else {
+		e.Error(fillAt(e, file, line, col, mask(e).Args(a))...)
 	}
 }
 
-// ErrorAtf outputs a formatted error message with location, unless e is nil.
+// ErrorAtf outputs a formatted error message with location, unless e is nil
+// or holds a nil pointer.
 func ErrorAtf(e Errorer, file string, line, col int, format string, a ...interface{}) {
+	if isNil(e) {
+		return
+	}
 	if h := thelper(e); h != nil {
 		h()
 	}
@@ -214,39 +383,98 @@ func ErrorAtf(e Errorer, file string, line, col int, format string, a ...interfa
 		ea.ErrorAt(file, line, col, fmt.Sprintf(m.Format(format), m.Args(a)...))
 	} else if ef, ok := e.(Errorfer); ok {
 		m := mask(e)
-		ef.Errorf(fillAtf(file, line, col, m.Format(format)), m.Args(a)...)
-	} else if e != nil {
+		ef.Errorf(fillAtf(ef, file, line, col, m.Format(format)), m.Args(a)...)
+	} else {
 		m := mask(e)
-		e.Error(fmt.Sprintf(fillAtf(file, line, col, m.Format(format)), m.Args(a)...))
+		e.Error(fmt.Sprintf(fillAtf(e, file, line, col, m.Format(format)), m.Args(a)...))
+	}
+}
+
+// osExit is replaced in tests.
+var osExit = os.Exit
+
+// Fatal outputs an error message like Error, and then exits the program with
+// status 1. If e implements Fataler, it owns the implementation instead, and
+// diag neither outputs the message nor exits. If e is nil or holds a nil
+// pointer, nothing is output, but the program still exits, so that Fatal
+// never returns to code that assumes it cannot continue.
+//
+// Like log.Fatal, Fatal exits immediately: deferred functions are not run,
+// so any cleanup they perform, such as flushing buffered output, is skipped.
+func Fatal(e Errorer, a ...interface{}) {
+	if isNil(e) {
+		osExit(1)
+		return
+	}
+	if h := thelper(e); h != nil {
+		h()
 	}
+	if f, ok := e.(Fataler); ok {
+		f.Fatal(mask(e).Args(a)...)
+		return
+	}
+	Error(e, a...)
+	osExit(1)
 }
 
-// Warning outputs an warning message, unless w is nil.
+// Fatalf outputs a formatted error message like Errorf, and then exits the
+// program with status 1. If e implements Fataler, it owns the implementation
+// instead, and diag neither outputs the message nor exits. Like Fatal, it
+// exits without output if e is nil or holds a nil pointer.
+//
+// Like log.Fatalf, Fatalf exits immediately: deferred functions are not run.
+func Fatalf(e Errorer, format string, a ...interface{}) {
+	if isNil(e) {
+		osExit(1)
+		return
+	}
+	if h := thelper(e); h != nil {
+		h()
+	}
+	if f, ok := e.(Fataler); ok {
+		m := mask(e)
+		f.Fatal(fmt.Sprintf(m.Format(format), m.Args(a)...))
+		return
+	}
+	Errorf(e, format, a...)
+	osExit(1)
+}
+
+// Warning outputs an warning message, unless w is nil or holds a nil pointer.
 func Warning(w Warninger, a ...interface{}) {
-	if w != nil {
-		if h := thelper(w); h != nil {
-			h()
-		}
-		w.Warning(mask(w).Args(a)...)
+	if isNil(w) {
+		return
+	}
+	if h := thelper(w); h != nil {
+		h()
 	}
+	w.Warning(mask(w).Args(a)...)
 }
 
-// Warningf outputs a formatted warning message, unless w is nil.
+// Warningf outputs a formatted warning message, unless w is nil or holds a
+// nil pointer.
 func Warningf(w Warninger, format string, a ...interface{}) {
+	if isNil(w) {
+		return
+	}
 	if h := thelper(w); h != nil {
 		h()
 	}
 	if wf, ok := w.(Warningfer); ok {
 		m := mask(w)
 		wf.Warningf(m.Format(format), m.Args(a)...)
-	} else if w != nil {
+	} else {
 		m := mask(w)
 		w.Warning(fmt.Sprintf(m.Format(format), m.Args(a)...))
 	}
 }
 
-// WarningAt outputs an warning message with location, unless w is nil.
+// WarningAt outputs an warning message with location, unless w is nil or
+// holds a nil pointer.
 func WarningAt(w Warninger, file string, line, col int, a ...interface{}) {
+	if isNil(w) {
+		return
+	}
 	if h := thelper(w); h != nil {
 		h()
 	}
@@ -254,13 +482,17 @@ func WarningAt(w Warninger, file string, line, col int, a ...interface{}) {
 		wa.WarningAt(file, line, col, mask(w).Args(a)...)
 	} else if wf, ok := w.(WarningAtfer); ok {
 		wf.WarningAtf(file, line, col, "%s", fmt.Sprint(mask(w).Args(a)...))
-	} else if w != nil {
-		w.Warning(fillAt(file, line, col, mask(w).Args(a))...)
+	} else {
+		w.Warning(fillAt(w, file, line, col, mask(w).Args(a))...)
 	}
 }
 
-// WarningAtf outputs a formatted warning message with location, unless w is nil.
+// WarningAtf outputs a formatted warning message with location, unless w is
+// nil or holds a nil pointer.
 func WarningAtf(w Warninger, file string, line, col int, format string, a ...interface{}) {
+	if isNil(w) {
+		return
+	}
 	if h := thelper(w); h != nil {
 		h()
 	}
@@ -272,38 +504,298 @@ func WarningAtf(w Warninger, file string, line, col int, format string, a ...int
 		wa.WarningAt(file, line, col, fmt.Sprintf(m.Format(format), m.Args(a)...))
 	} else if wf, ok := w.(Warningfer); ok {
 		m := mask(w)
-		wf.Warningf(fillAtf(file, line, col, m.Format(format)), m.Args(a)...)
-	} else if w != nil {
+		wf.Warningf(fillAtf(wf, file, line, col, m.Format(format)), m.Args(a)...)
+	} else {
 		m := mask(w)
-		w.Warning(fmt.Sprintf(fillAtf(file, line, col, m.Format(format)), m.Args(a)...))
+		w.Warning(fmt.Sprintf(fillAtf(w, file, line, col, m.Format(format)), m.Args(a)...))
+	}
+}
+
+// Info outputs an informational message, unless i is nil or holds a nil
+// pointer. If i does not implement Infoer, the message is output with Print.
+//
+// Info takes an Interface rather than an Infoer so that it works with any
+// Interface, as it was added late.
+func Info(i Interface, a ...interface{}) {
+	if isNil(i) {
+		return
+	}
+	if h := thelper(i); h != nil {
+		h()
+	}
+	if ii, ok := i.(Infoer); ok {
+		ii.Info(mask(i).Args(a)...)
+	} else {
+		Print(i, a...)
+	}
+}
+
+// Infof outputs a formatted informational message, unless i is nil or holds a
+// nil pointer. If i does not implement Infoer, the message is output with
+// Printf.
+func Infof(i Interface, format string, a ...interface{}) {
+	if isNil(i) {
+		return
+	}
+	if h := thelper(i); h != nil {
+		h()
+	}
+	if inf, ok := i.(Infofer); ok {
+		m := mask(i)
+		inf.Infof(m.Format(format), m.Args(a)...)
+	} else if ii, ok := i.(Infoer); ok {
+		m := mask(i)
+		ii.Info(fmt.Sprintf(m.Format(format), m.Args(a)...))
+	} else {
+		Printf(i, format, a...)
+	}
+}
+
+// InfoAt outputs an informational message with location, unless i is nil or
+// holds a nil pointer. If i does not implement Infoer, the message is output
+// with PrintAt.
+func InfoAt(i Interface, file string, line, col int, a ...interface{}) {
+	if isNil(i) {
+		return
+	}
+	if h := thelper(i); h != nil {
+		h()
+	}
+	if ia, ok := i.(InfoAter); ok {
+		ia.InfoAt(file, line, col, mask(i).Args(a)...)
+	} else if inf, ok := i.(InfoAtfer); ok {
+		inf.InfoAtf(file, line, col, "%s", fmt.Sprint(mask(i).Args(a)...))
+	} else if ii, ok := i.(Infoer); ok {
+		ii.Info(fillAt(ii, file, line, col, mask(i).Args(a))...)
+	} else {
+		PrintAt(i, file, line, col, a...)
+	}
+}
+
+// InfoAtf outputs a formatted informational message with location, unless i
+// is nil or holds a nil pointer. If i does not implement Infoer, the message
+// is output with PrintAtf.
+func InfoAtf(i Interface, file string, line, col int, format string, a ...interface{}) {
+	if isNil(i) {
+		return
+	}
+	if h := thelper(i); h != nil {
+		h()
+	}
+	if iaf, ok := i.(InfoAtfer); ok {
+		m := mask(i)
+		iaf.InfoAtf(file, line, col, m.Format(format), m.Args(a)...)
+	} else if ia, ok := i.(InfoAter); ok {
+		m := mask(i)
+		ia.InfoAt(file, line, col, fmt.Sprintf(m.Format(format), m.Args(a)...))
+	} else if inf, ok := i.(Infofer); ok {
+		m := mask(i)
+		inf.Infof(fillAtf(inf, file, line, col, m.Format(format)), m.Args(a)...)
+	} else if ii, ok := i.(Infoer); ok {
+		m := mask(i)
+		ii.Info(fmt.Sprintf(fillAtf(ii, file, line, col, m.Format(format)), m.Args(a)...))
+	} else {
+		PrintAtf(i, file, line, col, format, a...)
 	}
 }
 
 // MaskValue requests that instances of v are obscured from output. If d
 // implements ValueMasker, it fully owns the implementation. If d does not
 // implement ValueMasker, then diag will obscure non-overlapping v from string
-// arguments to the various output functions. (Print, Debugf, WarningAt, etc.)
+// arguments to the various output functions (Print, Debugf, WarningAt, etc.),
+// and from the text of error and fmt.Stringer arguments.
 //
 // Diag will not obscure filenames passed to the ...At or ...Atf variants, nor
 // will it attempt to obscure arguments that combine to form a requested masked
 // value.
 func MaskValue(d Interface, v string) {
+	if m, ok := d.(ValueMasker); ok {
+		m.MaskValue(v)
+	} else if d != nil && !masksValue(d, v) {
+		updateMasker(d, func(m *masker) {
+			if !m.has(v) {
+				m.add(v)
+			}
+		})
+	}
+}
+
+// MaskExcept requests that instances of v are obscured from output like
+// MaskValue, except where they occur within one of the except strings, such
+// as a public prefix that happens to contain v. Exceptions apply only to v:
+// other values masked on d are still obscured where they occur within them.
+// Registering the same exception for v again has no effect, and UnmaskValue
+// removes v's exceptions along with v.
+//
+// Exceptions take precedence over masked values that start at the same
+// position, and over masked values that start within them; a masked value
+// that starts before an exception is still obscured. Among overlapping
+// exceptions, the one starting first wins, or if they start at the same
+// position, the one registered first.
+//
+// If d implements ValueMasker, it cannot express exceptions, so v is masked
+// with MaskValue everywhere.
+func MaskExcept(d Interface, v string, except ...string) {
 	if m, ok := d.(ValueMasker); ok {
 		m.MaskValue(v)
 	} else if d != nil {
-		if maskers == nil {
-			maskers = make(map[interface{}]*masker)
-		}
-		m := maskers[d]
-		if m == nil {
-			m = &masker{}
-			maskers[d] = m
-		}
-		m.masked = append(m.masked, v, "***")
-		m.repl = nil
+		updateMasker(d, func(m *masker) {
+			for _, e := range except {
+				if !m.keeps(e, v) {
+					m.kept = append(m.kept, exception{e, v})
+				}
+			}
+			if !m.has(v) {
+				m.add(v)
+			}
+		})
+	}
+}
+
+// MaskRegexp requests that matches of re are obscured from output, like
+// MaskValue does for a literal value. This suits secrets whose values are not
+// known in advance, such as bearer tokens.
+//
+// Literal values are replaced first, and then each pattern in the order they
+// were registered, each replacing the leftmost non-overlapping matches of the
+// result so far. Since ValueMasker can only express literal values, diag
+// applies re even if d implements ValueMasker.
+func MaskRegexp(d Interface, re *regexp.Regexp) {
+	if d != nil {
+		updateMasker(d, func(m *masker) {
+			m.patterns = append(m.patterns, re)
+		})
+	}
+}
+
+// UnmaskValue reverses MaskValue or MaskExcept for v, so that it is no longer
+// obscured from output. If d implements ValueUnmasker, it fully owns the
+// implementation. Masks held in a context by MaskInContext are unaffected.
+func UnmaskValue(d Interface, v string) {
+	if u, ok := d.(ValueUnmasker); ok {
+		u.UnmaskValue(v)
+	} else if d != nil {
+		updateMasker(d, func(m *masker) {
+			masked := m.masked
+			m.masked, m.index = nil, nil // not m.masked[:0] or m.index, which old shares
+			for _, mv := range masked {
+				if mv != v {
+					m.add(mv)
+				}
+			}
+			var kept []exception
+			for _, e := range m.kept {
+				if e.value != v {
+					kept = append(kept, e)
+				}
+			}
+			m.kept = kept
+		})
 	}
 }
 
+// ClearMasks reverses all calls to MaskValue, MaskExcept, MaskRegexp, and
+// SetMaskReplacement for d. If d implements MaskClearer, it fully owns the
+// implementation. Masks held in a context by MaskInContext are unaffected.
+//
+// Since diag holds masks for each instance until they are cleared, this
+// allows a long-lived process to release masks it no longer needs.
+func ClearMasks(d Interface) {
+	if c, ok := d.(MaskClearer); ok {
+		c.ClearMasks()
+	} else if d != nil {
+		updateMasker(d, func(m *masker) {
+			*m = masker{}
+		})
+	}
+}
+
+// SetMaskReplacement changes the text that replaces values and patterns
+// masked on d from the default "***" to repl. An empty repl removes masked
+// text entirely. It applies to values masked both before and after the call.
+// Masks held in a context by MaskInContext keep the default, as do any
+// implemented by d itself.
+func SetMaskReplacement(d Interface, repl string) {
+	if d != nil {
+		updateMasker(d, func(m *masker) {
+			m.replacement = &repl
+			if repl == "***" {
+				m.replacement = nil
+			}
+		})
+	}
+}
+
+// updateMasker replaces the masker for d with a copy modified by fn, or
+// removes it if nothing remains masked. Functions passed as fn may append to
+// the slices of the copy, but must not modify their existing elements.
+// Maskers are not modified once stored, apart from building their replacer
+// once, so mask can return them without holding a lock while they are used.
+func updateMasker(d interface{}, fn func(*masker)) {
+	maskersMu.Lock()
+	defer maskersMu.Unlock()
+	m := &masker{}
+	if old := maskers[d]; old != nil {
+		// Share the slices and index of old, so that registering values in
+		// turn does not copy all those before. Appending past their length
+		// leaves the view of old unchanged, and only the stored masker is
+		// derived from or consults the index.
+		m.masked = old.masked
+		m.index = old.index
+		m.kept = old.kept
+		m.patterns = old.patterns
+		m.replacement = old.replacement
+	}
+	fn(m)
+	if len(m.masked) == 0 && len(m.patterns) == 0 && m.replacement == nil {
+		delete(maskers, d)
+		atomic.StoreInt32(&maskersLen, int32(len(maskers)))
+		return
+	}
+	m.repl = &lazyReplacer{}
+	if maskers == nil {
+		maskers = make(map[interface{}]*masker)
+	}
+	maskers[d] = m
+	atomic.StoreInt32(&maskersLen, int32(len(maskers)))
+}
+
+// Forget clears all per-instance state diag stores for d, restoring its
+// default behavior. Currently this is its masks, which Forget clears with
+// ClearMasks, so if d implements MaskClearer it is called. Any patterns diag
+// applies for d with MaskRegexp are cleared either way. It does not affect
+// masks held in a context by MaskInContext.
+//
+// This is useful as a t.Cleanup for a logger shared across tests.
+func Forget(d Interface) {
+	ClearMasks(d)
+	if _, ok := d.(MaskClearer); ok {
+		updateMasker(d, func(m *masker) {
+			*m = masker{}
+		})
+	}
+}
+
+// MaskInContext returns a copy of ctx that requests instances of v are
+// obscured from output issued through a Context carrying it. These masks
+// apply in addition to any registered on the Context by MaskValue, and are
+// inherited by contexts derived from the returned one.
+//
+// This scopes masking to a single request or operation: a shared Interface
+// wrapped by WithContext for each request will only obscure the values masked
+// in that request's context.
+func MaskInContext(ctx context.Context, v string) context.Context {
+	m := &masker{}
+	if outer, ok := ctx.Value(maskContextKey{}).(*masker); ok {
+		m.masked = append(m.masked, outer.masked...)
+	}
+	m.masked = append(m.masked, v)
+	m.repl = &lazyReplacer{}
+	return context.WithValue(ctx, maskContextKey{}, m)
+}
+
+type maskContextKey struct{}
+
 // FormatAtBracket returns a substring of `[{{ file }}:{{ line }}.{{ col }}]`
 // It terminates the inner string at the first zero value, and returns nothing
 // if file is empty.
@@ -322,6 +814,37 @@ func FormatAtBracket(file string, line, col int) string {
 	return loc
 }
 
+// FormatAtOffset is like FormatAtBracket, except that it keeps col even when
+// line is zero, separating it with an empty line as `[{{ file }}:.{{ col }}]`.
+// This suits single-line inputs whose columns are counted from an offset.
+func FormatAtOffset(file string, line, col int) string {
+	if file == "" {
+		return ""
+	}
+	if line == 0 && col != 0 {
+		return "[" + file + ":." + strconv.Itoa(col) + "]"
+	}
+	return FormatAtBracket(file, line, col)
+}
+
+// FormatAtGNU returns a substring of `{{ file }}:{{ line }}:{{ col }}:`, the
+// style expected by editors and errorformat configurations. Like
+// FormatAtBracket, it terminates at the first zero value, such as
+// `file:line:`, and returns nothing if file is empty.
+func FormatAtGNU(file string, line, col int) string {
+	if file == "" {
+		return ""
+	}
+	loc := file + ":"
+	if line != 0 {
+		loc += strconv.Itoa(line) + ":"
+		if col != 0 {
+			loc += strconv.Itoa(col) + ":"
+		}
+	}
+	return loc
+}
+
 // FormatAt globally specifies the format used for At information with
 // diag.Interfaces that don't implement ...At variants. Defaults to FallbackAt.
 //
@@ -330,24 +853,46 @@ func FormatAtBracket(file string, line, col int) string {
 // ignored, but this is not enforced by diag.
 //
 // If you need different behaviors for warning and error, you should implement
-// the ...At variants directly.
+// the ...At variants directly. To format locations differently for a single
+// diag.Interface, implement AtFormatter.
 var FormatAt = FormatAtBracket
 
-func fillAt(file string, line, col int, a []interface{}) []interface{} {
-	if loc := FormatAt(file, line, col); loc != "" {
+// formatAt formats a location with d's FormatAt method if it implements
+// AtFormatter, or with the global FormatAt otherwise.
+func formatAt(d interface{}, file string, line, col int) string {
+	if f, ok := d.(AtFormatter); ok {
+		return f.FormatAt(file, line, col)
+	}
+	return FormatAt(file, line, col)
+}
+
+func fillAt(d interface{}, file string, line, col int, a []interface{}) []interface{} {
+	if loc := formatAt(d, file, line, col); loc != "" {
 		return append([]interface{}{loc}, a...)
 	}
 	return a
 }
 
-func fillAtf(file string, line, col int, format string) string {
-	loc := FormatAt(file, line, col)
+func fillAtf(d interface{}, file string, line, col int, format string) string {
+	loc := formatAt(d, file, line, col)
 	if loc == "" {
 		return format
 	}
 	return strings.ReplaceAll(loc, "%", "%%") + " " + format
 }
 
+// isNil reports whether i is nil or a nil pointer. The output functions
+// assert to optional interfaces, which succeeds on a typed nil, so they all
+// check this before calling any methods. The comparison to nil comes first,
+// so that the untyped nil skips reflection.
+func isNil(i interface{}) bool {
+	if i == nil {
+		return true
+	}
+	v := reflect.ValueOf(i)
+	return v.Kind() == reflect.Ptr && v.IsNil()
+}
+
 // thelper retrieves a t.Helper() method if i implements it. This allows
 // diag to use t.Helper() to disappear from the logging locations.
 func thelper(i interface{}) func() {
@@ -360,40 +905,200 @@ func thelper(i interface{}) func() {
 }
 
 type masker struct {
-	masked []string
-	repl   *strings.Replacer
+	masked      []string         // values to replace
+	index       map[string]bool  // set of masked; see has
+	kept        []exception      // exceptions to preserve, matched before masked
+	repl        *lazyReplacer    // built from kept and masked on first use
+	patterns    []*regexp.Regexp // applied after repl
+	replacement *string          // replaces masked values, or "***" if nil
+	next        *masker          // applied after this one, e.g. for context-scoped masks
 }
 
-var maskers map[interface{}]*masker
+// exception is text registered by MaskExcept in which value is not masked.
+type exception struct {
+	text, value string
+}
 
-func mask(d interface{}) *masker {
-	m := maskers[d]
-	if m == nil || len(m.masked) == 0 {
+// lazyReplacer holds the strings.Replacer of a masker, built on first use, so
+// that registering many values in turn does not rebuild it for each.
+type lazyReplacer struct {
+	once sync.Once
+	r    *strings.Replacer
+}
+
+// replacer returns the strings.Replacer for the exceptions and values of m,
+// or nil if m masks no values.
+func (m *masker) replacer() *strings.Replacer {
+	if len(m.masked) == 0 {
+		return nil
+	}
+	m.repl.once.Do(func() {
+		pairs := make([]string, 0, 2*(len(m.kept)+len(m.masked)))
+		done := make(map[string]bool, len(m.kept))
+		for _, e := range m.kept {
+			if !done[e.text] {
+				done[e.text] = true
+				pairs = append(pairs, e.text, m.except(e.text))
+			}
+		}
+		for _, v := range m.masked {
+			pairs = append(pairs, v, m.with())
+		}
+		m.repl.r = strings.NewReplacer(pairs...)
+	})
+	return m.repl.r
+}
+
+// except returns the exception text with the values masked by m obscured,
+// apart from those it was registered for.
+func (m *masker) except(text string) string {
+	var pairs []string
+	for _, v := range m.masked {
+		if !m.keeps(text, v) {
+			pairs = append(pairs, v, m.with())
+		}
+	}
+	if len(pairs) == 0 {
+		return text
+	}
+	return strings.NewReplacer(pairs...).Replace(text)
+}
+
+// keeps reports whether text is registered as an exception for v.
+func (m *masker) keeps(text, v string) bool {
+	for _, e := range m.kept {
+		if e.text == text && e.value == v {
+			return true
+		}
+	}
+	return false
+}
+
+// with returns the text that replaces masked values.
+func (m *masker) with() string {
+	if m.replacement == nil {
+		return "***"
+	}
+	return *m.replacement
+}
+
+var (
+	maskersMu sync.RWMutex
+	maskers   map[interface{}]*masker
+
+	// maskersLen is len(maskers), kept so that programs that never mask
+	// values skip the lock and map lookup on each call.
+	maskersLen int32
+)
+
+// maskerFor returns the masker registered for d itself, if any.
+func maskerFor(d interface{}) *masker {
+	if atomic.LoadInt32(&maskersLen) == 0 {
 		return nil
 	}
-	if m.repl == nil {
-		m.repl = strings.NewReplacer(m.masked...)
+	maskersMu.RLock()
+	defer maskersMu.RUnlock()
+	return maskers[d]
+}
+
+func mask(d interface{}) *masker {
+	m := maskerFor(d)
+	if m != nil && len(m.masked) == 0 && len(m.patterns) == 0 {
+		m = nil
+	}
+	if ctx, ok := d.(context.Context); ok {
+		if cm, ok := ctx.Value(maskContextKey{}).(*masker); ok {
+			if m == nil {
+				return cm
+			}
+			return &masker{masked: m.masked, kept: m.kept, repl: m.repl, patterns: m.patterns, replacement: m.replacement, next: cm}
+		}
 	}
 	return m
 }
 
+// has reports whether v is among the values masked by m itself. Registering
+// a value again would only grow the replacer, so callers check this first.
+// Later snapshots share and extend the index, so has must only be called on
+// the stored masker, with maskersMu held.
+func (m *masker) has(v string) bool {
+	return m != nil && m.index[v]
+}
+
+// add appends v to the values masked by m.
+func (m *masker) add(v string) {
+	if m.index == nil {
+		m.index = make(map[string]bool)
+	}
+	m.index[v] = true
+	m.masked = append(m.masked, v)
+}
+
+// masksValue reports whether v is among the values masked on d itself.
+func masksValue(d interface{}, v string) bool {
+	if atomic.LoadInt32(&maskersLen) == 0 {
+		return false
+	}
+	maskersMu.RLock()
+	defer maskersMu.RUnlock()
+	return maskers[d].has(v)
+}
+
 func (m *masker) Args(a []interface{}) []interface{} {
 	if m == nil {
 		return a
 	}
-	repl := m.repl
-	a = append([]interface{}(nil), a...)
+	// Copy a only once something changes, as it belongs to the caller.
+	masked, copied := a, false
 	for i := range a {
-		if s, ok := a[i].(string); ok {
-			a[i] = repl.Replace(s)
+		var r interface{}
+		switch v := a[i].(type) {
+		case string:
+			if s := m.replace(v); s != v {
+				r = s
+			}
+		case error, fmt.Stringer:
+			if s, ok := m.replaceText(v).(string); ok {
+				r = s
+			}
+		}
+		if r == nil {
+			continue
 		}
+		if !copied {
+			masked, copied = append([]interface{}(nil), a...), true
+		}
+		masked[i] = r
 	}
-	return a
+	return masked
+}
+
+// replaceText returns the masked text of an error or fmt.Stringer, or v
+// itself if nothing in its text is masked, so that it keeps its own
+// formatting. Using fmt.Sprint recovers from panics such as nil receivers.
+func (m *masker) replaceText(v interface{}) interface{} {
+	s := fmt.Sprint(v)
+	if r := m.replace(s); r != s {
+		return r
+	}
+	return v
 }
 
 func (m *masker) Format(format string) string {
 	if m == nil {
 		return format
 	}
-	return m.repl.Replace(format)
+	return m.replace(format)
+}
+
+func (m *masker) replace(s string) string {
+	for ; m != nil; m = m.next {
+		if r := m.replacer(); r != nil {
+			s = r.Replace(s)
+		}
+		for _, re := range m.patterns {
+			s = re.ReplaceAllLiteralString(s, m.with())
+		}
+	}
+	return s
 }
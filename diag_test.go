@@ -1,9 +1,15 @@
 package diag_test
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
+	"regexp"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/mutility/diag"
 )
@@ -17,6 +23,8 @@ func TestNil(t *testing.T) {
 	for name, fn := range map[string]func(){
 		"Debug":      func() { diag.Debug(nil, args...) },
 		"Debugf":     func() { diag.Debugf(nil, format, args...) },
+		"DebugAt":    func() { diag.DebugAt(nil, file, line, col, args...) },
+		"DebugAtf":   func() { diag.DebugAtf(nil, file, line, col, format, args...) },
 		"Print":      func() { diag.Print(nil, args...) },
 		"Printf":     func() { diag.Printf(nil, format, args...) },
 		"Warning":    func() { diag.Warning(nil, args...) },
@@ -39,6 +47,58 @@ func TestNil(t *testing.T) {
 	}
 }
 
+// TestTypedNil verifies that every level treats a typed nil like nil.
+func TestTypedNil(t *testing.T) {
+	var d *fill
+	for name, fn := range map[string]func(){
+		"Debug":          func() { diag.Debug(d, "a") },
+		"Debugf":         func() { diag.Debugf(d, "%s", "a") },
+		"DebugAt":        func() { diag.DebugAt(d, "f", 1, 2, "a") },
+		"DebugAtf":       func() { diag.DebugAtf(d, "f", 1, 2, "%s", "a") },
+		"Info":           func() { diag.Info(d, "a") },
+		"InfoAtf":        func() { diag.InfoAtf(d, "f", 1, 2, "%s", "a") },
+		"Print":          func() { diag.Print(d, "a") },
+		"Printf":         func() { diag.Printf(d, "%s", "a") },
+		"Warning":        func() { diag.Warning(d, "a") },
+		"Warningf":       func() { diag.Warningf(d, "%s", "a") },
+		"WarningAt":      func() { diag.WarningAt(d, "f", 1, 2, "a") },
+		"WarningAtf":     func() { diag.WarningAtf(d, "f", 1, 2, "%s", "a") },
+		"WarningAtRange": func() { diag.WarningAtRange(d, "f", 1, 2, 3, 4, "a") },
+		"Error":          func() { diag.Error(d, "a") },
+		"Errorf":         func() { diag.Errorf(d, "%s", "a") },
+		"ErrorAt":        func() { diag.ErrorAt(d, "f", 1, 2, "a") },
+		"ErrorAtf":       func() { diag.ErrorAtf(d, "f", 1, 2, "%s", "a") },
+		"ErrorAtRangef":  func() { diag.ErrorAtRangef(d, "f", 1, 2, 3, 4, "%s", "a") },
+	} {
+		t.Run(name, func(t *testing.T) {
+			defer func() {
+				if p := recover(); p != nil {
+					t.Error("recovered from panic:", p)
+				}
+			}()
+			fn()
+		})
+	}
+}
+
+func BenchmarkPrintNil(b *testing.B) {
+	var d *fill
+	for i := 0; i < b.N; i++ {
+		diag.Print(d, "a")
+	}
+}
+
+// BenchmarkPrintfUnmasked measures the common case of a program that never
+// masks values, which should neither allocate nor consult the masks. Tests
+// that mask values forget them when they finish, so that none remain here.
+func BenchmarkPrintfUnmasked(b *testing.B) {
+	d := diag.Discard
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		diag.Printf(d, "format")
+	}
+}
+
 // TestFill ensures that ...f, ...At, and ...Atf methods are wired to the underlying
 func TestFill(t *testing.T) {
 	d := &fill{}
@@ -49,6 +109,8 @@ func TestFill(t *testing.T) {
 	for name, fn := range map[string]func() string{
 		"Debug":      func() string { diag.Debug(d, args...); return d.debug() },
 		"Debugf":     func() string { diag.Debugf(d, format, args...); return d.debug() },
+		"DebugAt":    func() string { diag.DebugAt(d, file, line, col, args...); return d.debug() },
+		"DebugAtf":   func() string { diag.DebugAtf(d, file, line, col, format, args...); return d.debug() },
 		"Print":      func() string { diag.Print(d, args...); return d.print() },
 		"Printf":     func() string { diag.Printf(d, format, args...); return d.print() },
 		"Warning":    func() string { diag.Warning(d, args...); return d.warning() },
@@ -80,6 +142,7 @@ func TestFill(t *testing.T) {
 func TestFillMask(t *testing.T) {
 	d := &fill{}
 	diag.MaskValue(d, "abc")
+	t.Cleanup(func() { diag.Forget(d) })
 	format := "%s%s%s %q"
 	args := []interface{}{"a", "b", "c", "abc"}
 	file := "somefile.abc"
@@ -87,6 +150,8 @@ func TestFillMask(t *testing.T) {
 	for name, fn := range map[string]func() string{
 		"Debug":      func() string { diag.Debug(d, args...); return d.debug() },
 		"Debugf":     func() string { diag.Debugf(d, format, args...); return d.debug() },
+		"DebugAt":    func() string { diag.DebugAt(d, file, line, col, args...); return d.debug() },
+		"DebugAtf":   func() string { diag.DebugAtf(d, file, line, col, format, args...); return d.debug() },
 		"Print":      func() string { diag.Print(d, args...); return d.print() },
 		"Printf":     func() string { diag.Printf(d, format, args...); return d.print() },
 		"Warning":    func() string { diag.Warning(d, args...); return d.warning() },
@@ -114,6 +179,332 @@ func TestFillMask(t *testing.T) {
 	}
 }
 
+// TestMaskExcept verifies that exceptions are preserved while other
+// occurrences are masked.
+func TestMaskExcept(t *testing.T) {
+	d := &fill{}
+	diag.MaskExcept(d, "key", "public-key", "keyboard")
+	diag.MaskValue(d, "token")
+	t.Cleanup(func() { diag.Forget(d) })
+	for _, tt := range []struct{ in, want string }{
+		{"key", "***\n"},
+		{"the public-key is not a key", "the public-key is not a ***\n"},
+		{"keyboard key token", "keyboard *** ***\n"},
+		{"monkey", "mon***\n"},
+	} {
+		diag.Print(d, tt.in)
+		if got := d.print(); got != tt.want {
+			t.Errorf("Print(%q): got %q; want %q", tt.in, got, tt.want)
+		}
+		diag.Printf(d, tt.in)
+		if got := d.print(); got != tt.want {
+			t.Errorf("Printf(%q): got %q; want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+// TestMaskExceptPerValue verifies that exceptions apply only to the value
+// they were registered with, and are removed with it.
+func TestMaskExceptPerValue(t *testing.T) {
+	d := &fill{}
+	diag.MaskExcept(d, "key", "public-key")
+	diag.MaskExcept(d, "pub", "pubsub")
+	diag.MaskExcept(d, "key", "public-key") // again, no effect
+	for _, tt := range []struct{ in, want string }{
+		{"public-key", "***lic-key\n"},
+		{"pubsub key", "pubsub ***\n"},
+		{"pub public-key", "*** ***lic-key\n"},
+	} {
+		diag.Print(d, tt.in)
+		if got := d.print(); got != tt.want {
+			t.Errorf("Print(%q): got %q; want %q", tt.in, got, tt.want)
+		}
+	}
+
+	diag.UnmaskValue(d, "key")
+	diag.MaskValue(d, "key")
+	diag.Print(d, "public-key")
+	if got, want := d.print(), "***lic-***\n"; got != want {
+		t.Errorf("after unmask: got %q; want %q", got, want)
+	}
+	diag.Forget(d)
+}
+
+// TestForget verifies that Forget clears masks.
+func TestForget(t *testing.T) {
+	d := &fill{}
+	diag.MaskValue(d, "secret")
+	diag.Print(d, "secret")
+	if got, want := d.print(), "***\n"; got != want {
+		t.Errorf("before: got %q; want %q", got, want)
+	}
+	diag.Forget(d)
+	diag.Print(d, "secret")
+	if got, want := d.print(), "secret\n"; got != want {
+		t.Errorf("after: got %q; want %q", got, want)
+	}
+	diag.Forget(d) // forgetting again is harmless
+	diag.Forget(nil)
+}
+
+// TestForgetInstance verifies that Forget clears only the masks of d, and
+// defers to MaskClearer.
+func TestForgetInstance(t *testing.T) {
+	d, other := &fill{}, &fill{}
+	diag.MaskValue(d, "secret")
+	diag.MaskValue(other, "secret")
+	diag.Forget(d)
+	diag.Print(d, "secret")
+	diag.Print(other, "secret")
+	if got, want := d.print()+other.print(), "secret\n***\n"; got != want {
+		t.Errorf("got %q; want %q", got, want)
+	}
+	diag.Forget(other)
+
+	o := &ownsMasks{}
+	diag.MaskRegexp(o, regexp.MustCompile("secret"))
+	diag.Forget(o)
+	if got, want := fmt.Sprint(o.calls), "[clear]"; got != want {
+		t.Errorf("owned: got %v; want %v", got, want)
+	}
+	if diag.HasMasker(o) {
+		t.Error("patterns held after Forget")
+	}
+}
+
+func TestUnmask(t *testing.T) {
+	d := &fill{}
+	diag.MaskValue(d, "alpha")
+	diag.MaskExcept(d, "bravo", "bravos")
+	diag.MaskValue(d, "charlie")
+	diag.UnmaskValue(d, "alpha")
+	diag.UnmaskValue(d, "delta") // never masked
+	diag.Print(d, "alpha bravo bravos charlie")
+	if got, want := d.print(), "alpha *** bravos ***\n"; got != want {
+		t.Errorf("unmasked: got %q; want %q", got, want)
+	}
+
+	diag.UnmaskValue(d, "bravo")
+	diag.UnmaskValue(d, "charlie")
+	if diag.HasMasker(d) {
+		t.Error("masks held after unmasking all values")
+	}
+
+	diag.MaskValue(d, "alpha")
+	diag.MaskValue(d, "bravo")
+	diag.ClearMasks(d)
+	diag.Print(d, "alpha bravo")
+	if got, want := d.print(), "alpha bravo\n"; got != want {
+		t.Errorf("cleared: got %q; want %q", got, want)
+	}
+	if diag.HasMasker(d) {
+		t.Error("masks held after ClearMasks")
+	}
+	diag.ClearMasks(nil)
+	diag.UnmaskValue(nil, "alpha")
+
+	o := &ownsMasks{}
+	diag.UnmaskValue(o, "alpha")
+	diag.ClearMasks(o)
+	if got, want := fmt.Sprint(o.calls), "[unmask alpha clear]"; got != want {
+		t.Errorf("owned: got %v; want %v", got, want)
+	}
+}
+
+func TestMaskRegexp(t *testing.T) {
+	d := &fill{}
+	diag.MaskValue(d, "hunter2")
+	diag.MaskRegexp(d, regexp.MustCompile(`Bearer [A-Za-z0-9]+`))
+	diag.MaskRegexp(d, regexp.MustCompile(`\*{3}[0-9]`))
+	diag.Printf(d, "auth %s for hunter2 Bearer abc123", "hunter29")
+	if got, want := d.print(), "auth *** for *** ***\n"; got != want {
+		t.Errorf("masked: got %q; want %q", got, want)
+	}
+
+	diag.UnmaskValue(d, "hunter2")
+	diag.Print(d, "hunter2 Bearer xyz")
+	if got, want := d.print(), "hunter2 ***\n"; got != want {
+		t.Errorf("unmasked: got %q; want %q", got, want)
+	}
+
+	diag.ClearMasks(d)
+	if diag.HasMasker(d) {
+		t.Error("patterns held after ClearMasks")
+	}
+	diag.MaskRegexp(nil, regexp.MustCompile(`x`))
+}
+
+func TestMaskReplacement(t *testing.T) {
+	d := &fill{}
+	diag.MaskValue(d, "alpha")
+	diag.SetMaskReplacement(d, "[REDACTED]")
+	diag.MaskValue(d, "bravo")
+	diag.MaskRegexp(d, regexp.MustCompile(`c[a-z]+e`))
+	diag.Print(d, "alpha bravo charlie")
+	if got, want := d.print(), "[REDACTED] [REDACTED] [REDACTED]\n"; got != want {
+		t.Errorf("replaced: got %q; want %q", got, want)
+	}
+
+	diag.SetMaskReplacement(d, "")
+	diag.Print(d, "<alpha bravo charlie>")
+	if got, want := d.print(), "<  >\n"; got != want {
+		t.Errorf("empty: got %q; want %q", got, want)
+	}
+
+	diag.ClearMasks(d)
+	diag.MaskValue(d, "alpha")
+	diag.Print(d, "alpha")
+	if got, want := d.print(), "***\n"; got != want {
+		t.Errorf("cleared: got %q; want %q", got, want)
+	}
+	diag.Forget(d)
+}
+
+type secretStringer struct{ s string }
+
+func (s secretStringer) String() string { return "token=" + s.s }
+
+func TestMaskStringerError(t *testing.T) {
+	d := &fill{}
+	diag.MaskValue(d, "hunter2")
+	var nilStringer *strings.Builder
+	diag.Print(d, secretStringer{"hunter2"}, errors.New("bad hunter2"), 42, nilStringer)
+	if got, want := d.print(), "token=*** bad *** 42 <nil>\n"; got != want {
+		t.Errorf("Print: got %q; want %q", got, want)
+	}
+	diag.Printf(d, "%v: %v %d", errors.New("hunter2"), secretStringer{"ok"}, 7)
+	if got, want := d.print(), "***: token=ok 7\n"; got != want {
+		t.Errorf("Printf: got %q; want %q", got, want)
+	}
+	diag.Forget(d)
+}
+
+// TestMaskArgsUnchanged verifies masking never alters the caller's arguments.
+func TestMaskArgsUnchanged(t *testing.T) {
+	d := &fill{}
+	diag.MaskValue(d, "hunter2")
+	args := []interface{}{1, "hunter2", "plain"}
+	diag.Print(d, args...)
+	if got, want := d.print(), "1 *** plain\n"; got != want {
+		t.Errorf("got %q; want %q", got, want)
+	}
+	if args[1] != "hunter2" {
+		t.Errorf("caller's args changed to %v", args)
+	}
+	diag.Forget(d)
+}
+
+// TestMaskValueTwice verifies registering a value again is a no-op.
+func TestMaskValueTwice(t *testing.T) {
+	d := &fill{}
+	diag.MaskValue(d, "hunter2")
+	diag.MaskValue(d, "hunter2")
+	diag.MaskExcept(d, "hunter2", "hunter22")
+	if got := diag.MaskedValues(d); fmt.Sprint(got) != "[hunter2]" {
+		t.Errorf("masked %q; want once", got)
+	}
+	diag.UnmaskValue(d, "hunter2")
+	diag.Print(d, "hunter2")
+	if got, want := d.print(), "hunter2\n"; got != want {
+		t.Errorf("got %q; want %q", got, want)
+	}
+	diag.Forget(d)
+}
+
+// BenchmarkMaskValue measures registering n distinct secrets, and then
+// logging, which builds the replacer once. The repeated variants register
+// each secret twice, as a handler might, which should cost little more.
+func BenchmarkMaskValue(b *testing.B) {
+	for _, n := range []int{10, 100, 1000} {
+		secrets := make([]string, n)
+		for i := range secrets {
+			secrets[i] = fmt.Sprintf("secret%04d", i)
+		}
+		for _, repeat := range []int{1, 2} {
+			name := fmt.Sprintf("distinct-%d", n)
+			if repeat > 1 {
+				name = fmt.Sprintf("repeated-%d", n)
+			}
+			b.Run(name, func(b *testing.B) {
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					d := &struct{ diag.Interface }{diag.Discard}
+					for _, s := range secrets {
+						for r := 0; r < repeat; r++ {
+							diag.MaskValue(d, s)
+						}
+					}
+					diag.Print(d, "using", secrets[0])
+					diag.Forget(d)
+				}
+			})
+		}
+	}
+}
+
+// BenchmarkMaskArgs measures masking arguments when none of them change,
+// which should not copy them, and when one does.
+func BenchmarkMaskArgs(b *testing.B) {
+	d := &struct{ diag.Interface }{diag.Discard}
+	diag.MaskValue(d, "hunter2")
+	defer diag.Forget(d)
+	for _, bb := range []struct {
+		name string
+		args []interface{}
+	}{
+		{"nostrings", []interface{}{1, 2.5, true}},
+		{"unmasked", []interface{}{1, "plain", true}},
+		{"masked", []interface{}{1, "hunter2", true}},
+	} {
+		b.Run(bb.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				diag.Print(d, bb.args...)
+			}
+		})
+	}
+}
+
+type ownsMasks struct {
+	fill
+	calls []string
+}
+
+func (o *ownsMasks) UnmaskValue(v string) { o.calls = append(o.calls, "unmask "+v) }
+func (o *ownsMasks) ClearMasks()          { o.calls = append(o.calls, "clear") }
+
+// TestMaskInContext verifies context-scoped masks are isolated per context.
+func TestMaskInContext(t *testing.T) {
+	d := &fill{}
+	ctxA := diag.WithContext(diag.MaskInContext(context.Background(), "alpha"), d)
+	ctxB := diag.WithContext(diag.MaskInContext(context.Background(), "bravo"), d)
+	ctxAB := diag.WithContext(diag.MaskInContext(ctxA, "bravo"), d)
+	diag.MaskValue(ctxA, "shared") // per-instance masks combine with context masks
+	t.Cleanup(func() { diag.Forget(ctxA) })
+
+	for _, tt := range []struct {
+		name string
+		d    diag.Interface
+		want string
+	}{
+		{"none", d, "alpha bravo shared\n"},
+		{"A", ctxA, "*** bravo ***\n"},
+		{"B", ctxB, "alpha *** shared\n"},
+		{"AB", ctxAB, "*** *** shared\n"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			diag.Warning(tt.d, "alpha", "bravo", "shared")
+			if got := d.warning(); got != tt.want {
+				t.Errorf("Warning: got %q; want %q", got, tt.want)
+			}
+			diag.Errorf(tt.d, "alpha bravo %s", "shared")
+			if got := d.error(); got != tt.want {
+				t.Errorf("Errorf: got %q; want %q", got, tt.want)
+			}
+		})
+	}
+}
+
 // TestFillGroup ensures that ...f, ...At, and ...Atf methods are handled by Group
 func TestFillGroup(t *testing.T) {
 	d := &fill{}
@@ -200,10 +591,113 @@ func TestAt(t *testing.T) {
 			if got != tt.cwant {
 				t.Errorf("custom: got %q; want %q", got, tt.cwant)
 			}
+
+			diag.DebugAt(d, tt.file, tt.line, tt.col, tt.args)
+			if got := d.debug(); got != tt.want {
+				t.Errorf("fill debug: got %q; want %q", got, tt.want)
+			}
+
+			diag.DebugAt(c, tt.file, tt.line, tt.col, tt.args)
+			if got := c.debug(); got != tt.cwant {
+				t.Errorf("custom debug: got %q; want %q", got, tt.cwant)
+			}
+
+			diag.PrintAt(d, tt.file, tt.line, tt.col, tt.args)
+			if got := d.print(); got != tt.want {
+				t.Errorf("fill print: got %q; want %q", got, tt.want)
+			}
+
+			diag.PrintAt(c, tt.file, tt.line, tt.col, tt.args)
+			if got := c.print(); got != tt.cwant {
+				t.Errorf("custom print: got %q; want %q", got, tt.cwant)
+			}
 		})
 	}
 }
 
+func TestFormatAtOffset(t *testing.T) {
+	for _, tt := range []struct {
+		file      string
+		line, col int
+		want      string
+	}{
+		{"", 0, 0, ""},
+		{"", 10, 3, ""},
+		{"fn.go", 0, 0, "[fn.go]"},
+		{"fn.go", 0, 3, "[fn.go:.3]"},
+		{"fn.go", 10, 0, "[fn.go:10]"},
+		{"fn.go", 10, 3, "[fn.go:10.3]"},
+	} {
+		if got := diag.FormatAtOffset(tt.file, tt.line, tt.col); got != tt.want {
+			t.Errorf("FormatAtOffset(%q, %d, %d): got %q; want %q", tt.file, tt.line, tt.col, got, tt.want)
+		}
+	}
+}
+
+func TestAtGNU(t *testing.T) {
+	defer func(orig func(string, int, int) string) { diag.FormatAt = orig }(diag.FormatAt)
+	diag.FormatAt = diag.FormatAtGNU
+
+	d := &fill{}
+	for _, tt := range []struct {
+		why       string
+		file      string
+		line, col int
+		want      string
+	}{
+		{"noinfo", "", 0, 0, "args\n"},
+		{"nofile", "", 10, 3, "args\n"},
+		{"file", "fn.go", 0, 0, "fn.go: args\n"},
+		{"noline", "fn.go", 0, 3, "fn.go: args\n"},
+		{"line", "fn.go", 10, 0, "fn.go:10: args\n"},
+		{"all", "fn.go", 10, 3, "fn.go:10:3: args\n"},
+	} {
+		t.Run(tt.why, func(t *testing.T) {
+			diag.WarningAt(d, tt.file, tt.line, tt.col, "args")
+			if got := d.warning(); got != tt.want {
+				t.Errorf("WarningAt: got %q; want %q", got, tt.want)
+			}
+			diag.ErrorAtf(d, tt.file, tt.line, tt.col, "%s", "args")
+			if got := d.error(); got != tt.want {
+				t.Errorf("ErrorAtf: got %q; want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// atFormatted formats locations with its own function.
+type atFormatted struct {
+	diag.Interface
+	format func(file string, line, col int) string
+}
+
+func (a *atFormatted) FormatAt(file string, line, col int) string { return a.format(file, line, col) }
+
+func TestAtFormatter(t *testing.T) {
+	gnu := func(file string, line, col int) string { return fmt.Sprintf("%s:%d:%d:", file, line, col) }
+	var gsb, bsb strings.Builder
+	g := &atFormatted{diag.NewWriter(&gsb), gnu}
+	b := &atFormatted{diag.NewWriter(&bsb), diag.FormatAtBracket}
+
+	var wg sync.WaitGroup
+	for _, d := range []diag.Interface{g, b} {
+		wg.Add(1)
+		go func(d diag.Interface) {
+			defer wg.Done()
+			diag.ErrorAt(d, "fn.go", 10, 3, "e")
+			diag.WarningAtf(d, "fn.go", 11, 4, "w %d", 1)
+		}(d)
+	}
+	wg.Wait()
+
+	if got, want := gsb.String(), "fn.go:10:3: e\nfn.go:11:4: w 1\n"; got != want {
+		t.Errorf("gnu: got %q; want %q", got, want)
+	}
+	if got, want := bsb.String(), "[fn.go:10.3] e\n[fn.go:11.4] w 1\n"; got != want {
+		t.Errorf("bracket: got %q; want %q", got, want)
+	}
+}
+
 type customat struct {
 	fill
 }
@@ -212,6 +706,14 @@ func (c *customat) WarningAt(file string, line, col int, args ...interface{}) {
 	c.w = fmt.Sprintf("[%s|%d|%d]", file, line, col) + fmt.Sprintln(args...)
 }
 
+func (c *customat) DebugAt(file string, line, col int, args ...interface{}) {
+	c.d = fmt.Sprintf("[%s|%d|%d]", file, line, col) + fmt.Sprintln(args...)
+}
+
+func (c *customat) PrintAt(file string, line, col int, args ...interface{}) {
+	c.p = fmt.Sprintf("[%s|%d|%d]", file, line, col) + fmt.Sprintln(args...)
+}
+
 func (c *customat) ErrorAt(file string, line, col int, args ...interface{}) {
 	c.w = fmt.Sprintf("[%s|%d|%d]", file, line, col) + fmt.Sprintln(args...)
 }
@@ -238,8 +740,12 @@ func TestFallback(t *testing.T) {
 			}
 			test("Debug", func() { diag.Debug(d, "d") }, "Debug"+wants.base)
 			test("Debugf", func() { diag.Debugf(d, "d") }, "Debug"+wants.f)
+			test("DebugAt", func() { diag.DebugAt(d, "f", 1, 2, "d") }, "Debug"+wants.at)
+			test("DebugAtf", func() { diag.DebugAtf(d, "f", 1, 2, "d") }, "Debug"+wants.atf)
 			test("Print", func() { diag.Print(d, "d") }, "Print"+wants.base)
 			test("Printf", func() { diag.Printf(d, "d") }, "Print"+wants.f)
+			test("PrintAt", func() { diag.PrintAt(d, "f", 1, 2, "d") }, "Print"+wants.at)
+			test("PrintAtf", func() { diag.PrintAtf(d, "f", 1, 2, "d") }, "Print"+wants.atf)
 			test("Warning", func() { diag.Warning(d, "d") }, "Warning"+wants.base)
 			test("Warningf", func() { diag.Warningf(d, "d") }, "Warning"+wants.f)
 			test("WarningAt", func() { diag.WarningAt(d, "f", 1, 2, "d") }, "Warning"+wants.at)
@@ -252,6 +758,54 @@ func TestFallback(t *testing.T) {
 	}
 }
 
+// TestInfoFallback verifies which fallback is used for the Info family, which
+// ends with Print for implementations that predate it.
+func TestInfoFallback(t *testing.T) {
+	var got string
+	for d, wants := range map[diag.Interface]struct{ base, f, at, atf string }{
+		&hasf{&got}:                      {"Print", "Printf", "Print", "Printf"},
+		&hasinfo{hasf{&got}}:             {"Info", "Info", "Info", "Info"},
+		&hasinfof{hasinfo{hasf{&got}}}:   {"Info", "Infof", "Info", "Infof"},
+		&hasinfoat{hasinfo{hasf{&got}}}:  {"Info", "Info", "InfoAt", "InfoAt"},
+		&hasinfoatf{hasinfo{hasf{&got}}}: {"Info", "Info", "InfoAtf", "InfoAtf"}, // prefer Atf over base for At()
+	} {
+		t.Run(fmt.Sprint(d), func(t *testing.T) {
+			test := func(name string, fn func(), want string) {
+				t.Run(name, func(t *testing.T) {
+					fn()
+					if got != want {
+						t.Errorf("called %s, want %s", got, want)
+					}
+				})
+			}
+			test("Info", func() { diag.Info(d, "d") }, wants.base)
+			test("Infof", func() { diag.Infof(d, "d") }, wants.f)
+			test("InfoAt", func() { diag.InfoAt(d, "f", 1, 2, "d") }, wants.at)
+			test("InfoAtf", func() { diag.InfoAtf(d, "f", 1, 2, "d") }, wants.atf)
+		})
+	}
+}
+
+type hasinfo struct{ hasf }
+
+func (h *hasinfo) Info(...interface{}) { *h.called = "Info" }
+func (h *hasinfo) String() string      { return "hasInfo" }
+
+type hasinfof struct{ hasinfo }
+
+func (h *hasinfof) Infof(string, ...interface{}) { *h.called = "Infof" }
+func (h *hasinfof) String() string               { return "hasInfof" }
+
+type hasinfoat struct{ hasinfo }
+
+func (h *hasinfoat) InfoAt(string, int, int, ...interface{}) { *h.called = "InfoAt" }
+func (h *hasinfoat) String() string                          { return "hasInfoAt" }
+
+type hasinfoatf struct{ hasinfo }
+
+func (h *hasinfoatf) InfoAtf(string, int, int, string, ...interface{}) { *h.called = "InfoAtf" }
+func (h *hasinfoatf) String() string                                   { return "hasInfoAtf" }
+
 type hasf struct{ called *string }
 
 func (h *hasf) Debug(...interface{})            { *h.called = "Debug" }
@@ -310,6 +864,136 @@ func TestPrefix(t *testing.T) {
 	}
 }
 
+// TestPrefixLines verifies that each line is prefixed once, however the lines
+// are split across writes.
+func TestPrefixLines(t *testing.T) {
+	for _, tt := range []struct {
+		name   string
+		writes []string
+		want   string
+	}{
+		{"multi", []string{"a\nb\n"}, "p: a\np: b\n"},
+		{"partial", []string{"a\nb"}, "p: a\np: b"},
+		{"split", []string{"a", "b\n", "c\n"}, "p: ab\np: c\n"},
+		{"splitmulti", []string{"a\nb", "c\nd", "\n"}, "p: a\np: bc\np: d\n"},
+		{"empty", []string{"a", "", "\n"}, "p: a\n"},
+		{"blank", []string{"\n\n"}, "p: \np: \n"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			sb := &strings.Builder{}
+			p := diag.NewPrefixed(sb, "p:")
+			for _, w := range tt.writes {
+				n, err := p.Write([]byte(w))
+				if err != nil {
+					t.Error("unexpected write error:", err)
+				}
+				if n != len(w) {
+					t.Errorf("wrote %d bytes; want %d", n, len(w))
+				}
+			}
+			if got := sb.String(); got != tt.want {
+				t.Errorf("got %q; want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestPrefixConcurrent verifies a prefixed writer shared between goroutines
+// prefixes each line once. Run with -race to check its state is guarded.
+func TestPrefixConcurrent(t *testing.T) {
+	lb := &lockedBuilder{}
+	p := diag.NewPrefixed(lb, "E:")
+	d := diag.NewWriters(p, p, io.Discard)
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				diag.Error(d, "error")
+				diag.Warning(d, "warning")
+			}
+		}()
+	}
+	wg.Wait()
+	lines := strings.Split(strings.TrimSuffix(lb.String(), "\n"), "\n")
+	if len(lines) != 800 {
+		t.Errorf("got %d lines; want 800", len(lines))
+	}
+	for _, line := range lines {
+		if line != "E: error" && line != "E: warning" {
+			t.Errorf("got line %q", line)
+			break
+		}
+	}
+}
+
+func TestWriterLevel(t *testing.T) {
+	for _, tt := range []struct {
+		min  diag.Level
+		want string
+	}{
+		{diag.LevelDebug, "d\ni\np\nw\ne\n"},
+		{diag.LevelPrint, "p\nw\ne\n"},
+		{diag.LevelWarning, "w\ne\n"},
+		{diag.LevelError, "e\n"},
+	} {
+		sb := &strings.Builder{}
+		d := diag.NewWriterLevel(sb, tt.min)
+		diag.Debug(d, "d")
+		diag.Info(d, "i")
+		diag.Print(d, "p")
+		diag.Warning(d, "w")
+		diag.Error(d, "e")
+		if got := sb.String(); got != tt.want {
+			t.Errorf("%v: got %q; want %q", tt.min, got, tt.want)
+		}
+	}
+}
+
+// failWriter fails every write with its error.
+type failWriter struct{ err error }
+
+func (w failWriter) Write([]byte) (int, error) { return 0, w.err }
+
+func TestWriterErrors(t *testing.T) {
+	var got []string
+	handler := diag.WithErrorHandler(func(err error) { got = append(got, err.Error()) })
+	fail := func(name string) io.Writer { return failWriter{errors.New(name)} }
+	d := diag.NewWriters5(fail("errors"), fail("warnings"), fail("prints"), fail("infos"), fail("debugs"), handler)
+	diag.Debug(d, "d")
+	diag.Info(d, "i")
+	diag.Printf(d, "p")
+	diag.WarningAt(d, "fn.go", 1, 2, "w")
+	diag.Errorf(d, "e")
+	if want := "[debugs infos prints warnings errors]"; fmt.Sprint(got) != want {
+		t.Errorf("got %v; want %v", got, want)
+	}
+
+	diag.Error(diag.NewWriter(fail("silent")), "e") // no handler, no panic
+}
+
+func TestTimestamped(t *testing.T) {
+	clock := diag.NewFakeClock(time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC))
+	sb := &strings.Builder{}
+	w := diag.NewTimestamped(diag.NewPrefixed(sb, "E:"), "15:04:05", diag.WithClock(clock))
+	d := diag.NewWriter(w)
+
+	diag.Error(d, "one\ntwo")
+	clock.Advance(time.Second)
+	fmt.Fprint(w, "three")
+	clock.Advance(time.Second)
+	fmt.Fprint(w, " continued\nfour\n")
+
+	want := "E: 07:08:09 one\n" +
+		"E: 07:08:09 two\n" +
+		"E: 07:08:10 three continued\n" +
+		"E: 07:08:11 four\n"
+	if got := sb.String(); got != want {
+		t.Errorf("got %q; want %q", got, want)
+	}
+}
+
 // TestWriter verifies that NewWriter* do the expected.
 func TestWriter(t *testing.T) {
 	for _, tt := range []struct {
@@ -319,7 +1003,7 @@ func TestWriter(t *testing.T) {
 	}{
 		{
 			"NewWriter",
-			[]string{"print\nwarning\nerror\n"},
+			[]string{"info\nprint\nwarning\nerror\n"},
 			func(fn func(diag.Interface)) []string {
 				sb := strings.Builder{}
 				fn(diag.NewWriter(&sb))
@@ -328,7 +1012,7 @@ func TestWriter(t *testing.T) {
 		},
 		{
 			"NewWriterDebug",
-			[]string{"debug\nprint\nwarning\nerror\n"},
+			[]string{"debug\ninfo\nprint\nwarning\nerror\n"},
 			func(fn func(diag.Interface)) []string {
 				sb := strings.Builder{}
 				fn(diag.NewWriterDebug(&sb))
@@ -337,7 +1021,7 @@ func TestWriter(t *testing.T) {
 		},
 		{
 			"NewWriters",
-			[]string{"debug\n", "print\nwarning\n", "error\n"},
+			[]string{"debug\n", "info\nprint\nwarning\n", "error\n"},
 			func(fn func(diag.Interface)) []string {
 				d, w, e := strings.Builder{}, strings.Builder{}, strings.Builder{}
 				fn(diag.NewWriters(&e, &w, &d))
@@ -346,16 +1030,27 @@ func TestWriter(t *testing.T) {
 		},
 		{
 			"NewWriters4",
-			[]string{"debug\n", "print\n", "warning\n", "error\n"},
+			[]string{"debug\n", "info\nprint\n", "warning\n", "error\n"},
 			func(fn func(diag.Interface)) []string {
 				d, p, w, e := strings.Builder{}, strings.Builder{}, strings.Builder{}, strings.Builder{}
 				fn(diag.NewWriters4(&e, &w, &p, &d))
 				return []string{d.String(), p.String(), w.String(), e.String()}
 			},
 		},
+		{
+			"NewWriters5",
+			[]string{"debug\n", "info\n", "print\n", "warning\n", "error\n"},
+			func(fn func(diag.Interface)) []string {
+				d, i, p := strings.Builder{}, strings.Builder{}, strings.Builder{}
+				w, e := strings.Builder{}, strings.Builder{}
+				fn(diag.NewWriters5(&e, &w, &p, &i, &d))
+				return []string{d.String(), i.String(), p.String(), w.String(), e.String()}
+			},
+		},
 	} {
 		got := tt.test(func(d diag.Interface) {
 			diag.Debug(d, "debug")
+			diag.Info(d, "info")
 			diag.Print(d, "print")
 			diag.Warning(d, "warning")
 			diag.Error(d, "error")
@@ -374,3 +1069,97 @@ func TestWriter(t *testing.T) {
 		}
 	}
 }
+
+type fataler struct {
+	fill
+	fatal string
+}
+
+func (f *fataler) Fatal(a ...interface{}) { f.fatal = fmt.Sprintln(a...) }
+
+func TestFatal(t *testing.T) {
+	var exits []int
+	defer diag.SetOsExit(func(code int) { exits = append(exits, code) })()
+
+	sb := &strings.Builder{}
+	d := diag.NewWriter(sb)
+	diag.MaskValue(d, "secret")
+	t.Cleanup(func() { diag.Forget(d) })
+	diag.Fatal(d, "failed:", "secret")
+	diag.Fatalf(d, "failed: %d", 2)
+	if got, want := sb.String(), "failed: ***\nfailed: 2\n"; got != want {
+		t.Errorf("got %q; want %q", got, want)
+	}
+	if fmt.Sprint(exits) != "[1 1]" {
+		t.Errorf("exits: got %v; want [1 1]", exits)
+	}
+
+	exits = nil
+	f := &fataler{}
+	diag.MaskValue(f, "secret")
+	t.Cleanup(func() { diag.Forget(f) })
+	diag.Fatalf(f, "owned %s", "secret")
+	if got, want := f.fatal, "owned ***\n"; got != want {
+		t.Errorf("Fataler: got %q; want %q", got, want)
+	}
+	if f.error() != "" || exits != nil {
+		t.Errorf("Fataler: error %q, exits %v; want neither", f.e, exits)
+	}
+
+	exits = nil
+	var nf *fataler
+	var nw *fill
+	for name, fn := range map[string]func(){
+		"Fatal":             func() { diag.Fatal(nil, "a") },
+		"Fatalf":            func() { diag.Fatalf(nil, "%s", "a") },
+		"Fatal typed nil":   func() { diag.Fatal(nf, "a") },
+		"Fatalf typed nil":  func() { diag.Fatalf(nf, "%s", "a") },
+		"Fatal nil writer":  func() { diag.Fatal(nw, "a") },
+		"Fatalf nil writer": func() { diag.Fatalf(nw, "%s", "a") },
+	} {
+		t.Run(name, func(t *testing.T) {
+			defer func() {
+				if p := recover(); p != nil {
+					t.Error("recovered from panic:", p)
+				}
+			}()
+			exits = nil
+			fn()
+			if fmt.Sprint(exits) != "[1]" {
+				t.Errorf("exits: got %v; want [1]", exits)
+			}
+		})
+	}
+}
+
+// TestMaskConcurrent verifies that masks may be registered while other
+// goroutines log; run it with -race.
+func TestMaskConcurrent(t *testing.T) {
+	lb := &lockedBuilder{}
+	d := diag.NewWriter(lb)
+	defer diag.Forget(d)
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				diag.MaskValue(d, fmt.Sprintf("secret%d%d", i, j))
+				diag.MaskExcept(d, fmt.Sprintf("key%d%d", i, j), "public")
+			}
+		}(i)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				diag.Warningf(d, "value %s", "secret00")
+				diag.Error(d, "value", "secret00")
+			}
+		}()
+	}
+	wg.Wait()
+
+	diag.Error(d, "finally", "secret00")
+	if got := lb.String(); !strings.HasSuffix(got, "finally ***\n") {
+		t.Errorf("got %q; want final mask applied", got[strings.LastIndex(got[:len(got)-1], "\n")+1:])
+	}
+}
@@ -0,0 +1,159 @@
+// package diagslog adapts a log/slog Handler to diag.Interface, so that
+// diagnostics flow into the same handler as other structured logs. It is a
+// separate module so that diag itself does not require Go 1.21.
+package diagslog
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/mutility/diag"
+)
+
+// New returns a diag.Interface that sends each message to h as a record.
+// Debug messages are logged at slog.LevelDebug, info and print messages at
+// slog.LevelInfo, warnings at slog.LevelWarn, and errors at slog.LevelError.
+// Messages from the ...At variants carry "file", "line", and "col"
+// attributes, omitting any that are empty or zero.
+//
+// Records have no source location, as the caller cannot be identified
+// reliably through diag's fallbacks. Messages are masked by diag before they
+// reach h, as for any other diag.Interface.
+//
+// Group runs fn against an Interface that sends records to
+// h.WithGroup(title), so that the location attributes of its messages are
+// qualified by the group's title. Values masked on the result remain masked
+// within its groups.
+func New(h slog.Handler) diag.Interface {
+	return &handler{Context: context.Background(), h: h}
+}
+
+// NewContext returns a diag.Context like New, which passes ctx to h with
+// each record. GroupContext maps to slog.Handler.WithGroup like Group does.
+func NewContext(ctx context.Context, h slog.Handler) diag.Context {
+	return &handler{Context: ctx, h: h}
+}
+
+type handler struct {
+	context.Context
+	h      slog.Handler
+	parent *handler // the handler whose group this is, if any
+}
+
+func (h *handler) log(level slog.Level, file string, line, col int, msg string) {
+	if !h.h.Enabled(h.Context, level) {
+		return
+	}
+	// diag masks messages with the values masked on h, but not on the
+	// handlers h is a group of.
+	for p := h.parent; p != nil; p = p.parent {
+		msg = diag.Sprint(p, msg)
+	}
+	r := slog.NewRecord(time.Now(), level, msg, 0)
+	if file != "" {
+		r.AddAttrs(slog.String("file", file))
+	}
+	if line != 0 {
+		r.AddAttrs(slog.Int("line", line))
+	}
+	if col != 0 {
+		r.AddAttrs(slog.Int("col", col))
+	}
+	_ = h.h.Handle(h.Context, r)
+}
+
+func (h *handler) Group(title string, fn func(diag.Interface)) {
+	fn(&handler{h.Context, h.h.WithGroup(title), h})
+}
+
+func (h *handler) GroupContext(title string, fn func(diag.Context)) {
+	fn(&handler{h.Context, h.h.WithGroup(title), h})
+}
+
+func (h *handler) Debug(a ...interface{}) {
+	h.log(slog.LevelDebug, "", 0, 0, sprintln(a))
+}
+
+func (h *handler) Debugf(format string, a ...interface{}) {
+	h.log(slog.LevelDebug, "", 0, 0, fmt.Sprintf(format, a...))
+}
+
+func (h *handler) DebugAt(file string, line, col int, a ...interface{}) {
+	h.log(slog.LevelDebug, file, line, col, sprintln(a))
+}
+
+func (h *handler) DebugAtf(file string, line, col int, format string, a ...interface{}) {
+	h.log(slog.LevelDebug, file, line, col, fmt.Sprintf(format, a...))
+}
+
+func (h *handler) Info(a ...interface{}) {
+	h.log(slog.LevelInfo, "", 0, 0, sprintln(a))
+}
+
+func (h *handler) Infof(format string, a ...interface{}) {
+	h.log(slog.LevelInfo, "", 0, 0, fmt.Sprintf(format, a...))
+}
+
+func (h *handler) InfoAt(file string, line, col int, a ...interface{}) {
+	h.log(slog.LevelInfo, file, line, col, sprintln(a))
+}
+
+func (h *handler) InfoAtf(file string, line, col int, format string, a ...interface{}) {
+	h.log(slog.LevelInfo, file, line, col, fmt.Sprintf(format, a...))
+}
+
+func (h *handler) Print(a ...interface{}) {
+	h.log(slog.LevelInfo, "", 0, 0, sprintln(a))
+}
+
+func (h *handler) Printf(format string, a ...interface{}) {
+	h.log(slog.LevelInfo, "", 0, 0, fmt.Sprintf(format, a...))
+}
+
+func (h *handler) PrintAt(file string, line, col int, a ...interface{}) {
+	h.log(slog.LevelInfo, file, line, col, sprintln(a))
+}
+
+func (h *handler) PrintAtf(file string, line, col int, format string, a ...interface{}) {
+	h.log(slog.LevelInfo, file, line, col, fmt.Sprintf(format, a...))
+}
+
+func (h *handler) Warning(a ...interface{}) {
+	h.log(slog.LevelWarn, "", 0, 0, sprintln(a))
+}
+
+func (h *handler) Warningf(format string, a ...interface{}) {
+	h.log(slog.LevelWarn, "", 0, 0, fmt.Sprintf(format, a...))
+}
+
+func (h *handler) WarningAt(file string, line, col int, a ...interface{}) {
+	h.log(slog.LevelWarn, file, line, col, sprintln(a))
+}
+
+func (h *handler) WarningAtf(file string, line, col int, format string, a ...interface{}) {
+	h.log(slog.LevelWarn, file, line, col, fmt.Sprintf(format, a...))
+}
+
+func (h *handler) Error(a ...interface{}) {
+	h.log(slog.LevelError, "", 0, 0, sprintln(a))
+}
+
+func (h *handler) Errorf(format string, a ...interface{}) {
+	h.log(slog.LevelError, "", 0, 0, fmt.Sprintf(format, a...))
+}
+
+func (h *handler) ErrorAt(file string, line, col int, a ...interface{}) {
+	h.log(slog.LevelError, file, line, col, sprintln(a))
+}
+
+func (h *handler) ErrorAtf(file string, line, col int, format string, a ...interface{}) {
+	h.log(slog.LevelError, file, line, col, fmt.Sprintf(format, a...))
+}
+
+// sprintln formats a like fmt.Sprintln, without the trailing newline.
+func sprintln(a []interface{}) string {
+	return strings.TrimSuffix(fmt.Sprintln(a...), "\n")
+}
@@ -0,0 +1,66 @@
+package diagslog
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/mutility/diag"
+)
+
+func newText(sb *strings.Builder) slog.Handler {
+	return slog.NewTextHandler(sb, &slog.HandlerOptions{
+		Level: slog.LevelDebug,
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey && len(groups) == 0 {
+				return slog.Attr{}
+			}
+			return a
+		},
+	})
+}
+
+func TestNew(t *testing.T) {
+	sb := &strings.Builder{}
+	d := New(newText(sb))
+	diag.MaskValue(d, "secret")
+	diag.Debug(d, "d")
+	diag.Infof(d, "i %d", 1)
+	diag.PrintAt(d, "a.go", 1, 0, "p")
+	diag.WarningAtf(d, "a.go", 2, 3, "w %s", "secret")
+	diag.Error(d, "e")
+	diag.Group(d, "g", func(d diag.Interface) {
+		diag.ErrorAt(d, "b.go", 4, 5, "ge")
+		diag.MaskValue(d, "inner")
+		diag.Group(d, "h", func(d diag.Interface) {
+			diag.Printf(d, "%s %s", "secret", "inner")
+		})
+	})
+	diag.Print(d, "inner")
+	diag.Forget(d)
+
+	want := `level=DEBUG msg=d
+level=INFO msg="i 1"
+level=INFO msg=p file=a.go line=1
+level=WARN msg="w ***" file=a.go line=2 col=3
+level=ERROR msg=e
+level=ERROR msg=ge g.file=b.go g.line=4 g.col=5
+level=INFO msg="*** ***"
+level=INFO msg=inner
+`
+	if got := sb.String(); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestGroupContext(t *testing.T) {
+	sb := &strings.Builder{}
+	d := NewContext(context.Background(), newText(sb))
+	diag.GroupContext(d, "req", func(d diag.Context) {
+		diag.WarningAt(d, "c.go", 6, 0, "w")
+	})
+	if got, want := sb.String(), "level=WARN msg=w req.file=c.go req.line=6\n"; got != want {
+		t.Errorf("got %q; want %q", got, want)
+	}
+}
@@ -0,0 +1,7 @@
+module github.com/mutility/diag/diagslog
+
+go 1.21
+
+require github.com/mutility/diag v0.0.0
+
+replace github.com/mutility/diag => ../
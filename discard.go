@@ -0,0 +1,33 @@
+package diag
+
+// Discard is an Interface that drops all messages. It implements
+// FullInterface with empty methods, so no message is formatted, and groups
+// run fn against Discard itself.
+var Discard Interface = discard{}
+
+type discard struct{}
+
+var _ FullInterface = discard{}
+
+func (discard) Debug(a ...interface{})                                                 {}
+func (discard) Debugf(format string, a ...interface{})                                 {}
+func (discard) DebugAt(file string, line, col int, a ...interface{})                   {}
+func (discard) DebugAtf(file string, line, col int, format string, a ...interface{})   {}
+func (discard) Info(a ...interface{})                                                  {}
+func (discard) Infof(format string, a ...interface{})                                  {}
+func (discard) InfoAt(file string, line, col int, a ...interface{})                    {}
+func (discard) InfoAtf(file string, line, col int, format string, a ...interface{})    {}
+func (discard) Print(a ...interface{})                                                 {}
+func (discard) Printf(format string, a ...interface{})                                 {}
+func (discard) PrintAt(file string, line, col int, a ...interface{})                   {}
+func (discard) PrintAtf(file string, line, col int, format string, a ...interface{})   {}
+func (discard) Warning(a ...interface{})                                               {}
+func (discard) Warningf(format string, a ...interface{})                               {}
+func (discard) WarningAt(file string, line, col int, a ...interface{})                 {}
+func (discard) WarningAtf(file string, line, col int, format string, a ...interface{}) {}
+func (discard) Error(a ...interface{})                                                 {}
+func (discard) Errorf(format string, a ...interface{})                                 {}
+func (discard) ErrorAt(file string, line, col int, a ...interface{})                   {}
+func (discard) ErrorAtf(file string, line, col int, format string, a ...interface{})   {}
+func (discard) Group(title string, fn func(Interface))                                 { fn(Discard) }
+func (discard) MaskValue(v string)                                                     {}
@@ -0,0 +1,39 @@
+package diag_test
+
+import (
+	"testing"
+
+	"github.com/mutility/diag"
+)
+
+func TestDiscard(t *testing.T) {
+	ran := false
+	diag.Group(diag.Discard, "g", func(d diag.Interface) {
+		ran = true
+		if d != diag.Discard {
+			t.Errorf("group got %v; want Discard", d)
+		}
+		diag.Error(d, "e")
+	})
+	if !ran {
+		t.Error("group did not run fn")
+	}
+	diag.MaskValue(diag.Discard, "secret")
+	if diag.HasMasker(diag.Discard) {
+		t.Error("Discard registered a masker")
+	}
+}
+
+// BenchmarkDiscard measures the cost of the discard path itself. Arguments are
+// omitted since their variadic slice escapes, and is allocated by the caller,
+// for any implementation.
+func BenchmarkDiscard(b *testing.B) {
+	d := diag.Discard
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		diag.Print(d)
+		diag.Warningf(d, "format")
+		diag.ErrorAt(d, "fn.go", 1, 2)
+		diag.Group(d, "g", func(diag.Interface) {})
+	}
+}
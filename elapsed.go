@@ -0,0 +1,16 @@
+package diag
+
+import "fmt"
+
+// NewElapsed creates an Interface that prefixes each message with the time
+// elapsed since it was created, such as "[+1.234s]", before forwarding it to
+// inner. Times come from the Clock passed to WithClock, if any, and otherwise
+// from the system clock.
+func NewElapsed(inner Interface, opts ...Option) Interface {
+	o := newOptions(opts)
+	start := o.now()
+	return &funnel{emit: func(m Diagnostic) {
+		m.Msg = fmt.Sprintf("[+%.3fs] %s", o.now().Sub(start).Seconds(), m.Msg)
+		forward(inner, m)
+	}}
+}
@@ -0,0 +1,25 @@
+package diag_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mutility/diag"
+)
+
+func TestElapsed(t *testing.T) {
+	clock := diag.NewFakeClock(time.Date(2021, 3, 4, 5, 6, 7, 0, time.UTC))
+	sb := &strings.Builder{}
+	d := diag.NewElapsed(diag.NewWriter(sb), diag.WithClock(clock))
+	diag.Print(d, "start")
+	clock.Advance(1234 * time.Millisecond)
+	diag.Warningf(d, "%s", "later")
+	clock.Advance(time.Minute)
+	diag.ErrorAt(d, "fn.go", 1, 2, "much later")
+
+	want := "[+0.000s] start\n[+1.234s] later\n[fn.go:1.2] [+61.234s] much later\n"
+	if got := sb.String(); got != want {
+		t.Errorf("got %q; want %q", got, want)
+	}
+}
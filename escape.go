@@ -0,0 +1,16 @@
+package diag
+
+import "strings"
+
+var whitespaceEscaper = strings.NewReplacer("\n", `\n`, "\t", `\t`, "\r", `\r`)
+
+// NewEscapeWhitespace creates an Interface that replaces newlines, tabs, and
+// carriage returns in each rendered message with the escapes \n, \t, and \r
+// before forwarding it to inner. This keeps each diagnostic on a single line
+// for line-oriented sinks.
+func NewEscapeWhitespace(inner Interface) Interface {
+	return &funnel{emit: func(m Diagnostic) {
+		m.Msg = whitespaceEscaper.Replace(m.Msg)
+		forward(inner, m)
+	}}
+}
@@ -0,0 +1,22 @@
+package diag_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mutility/diag"
+)
+
+func TestEscapeWhitespace(t *testing.T) {
+	sb := &strings.Builder{}
+	d := diag.NewEscapeWhitespace(diag.NewWriter(sb))
+	diag.Print(d, "two\nlines")
+	diag.Warningf(d, "%s\t%s", "tabbed", "value")
+	diag.ErrorAt(d, "fn.go", 1, 2, "crlf\r\n")
+	want := `two\nlines` + "\n" +
+		`tabbed\tvalue` + "\n" +
+		`[fn.go:1.2] crlf\r\n` + "\n"
+	if got := sb.String(); got != want {
+		t.Errorf("got %q; want %q", got, want)
+	}
+}
@@ -0,0 +1,36 @@
+package diag
+
+// SetOsExit replaces the function used by Fatal and Fatalf to exit, and
+// returns a function that restores it.
+func SetOsExit(exit func(int)) (restore func()) {
+	old := osExit
+	osExit = exit
+	return func() { osExit = old }
+}
+
+// HasMasker reports whether diag holds masks for d.
+func HasMasker(d interface{}) bool {
+	maskersMu.RLock()
+	defer maskersMu.RUnlock()
+	_, ok := maskers[d]
+	return ok
+}
+
+// MaskedValues returns the values diag masks for d itself.
+func MaskedValues(d interface{}) []string {
+	if m := maskerFor(d); m != nil {
+		return append([]string(nil), m.masked...)
+	}
+	return nil
+}
+
+// FlapTracked returns the number of distinct warnings a flap detector tracks.
+func FlapTracked(d Interface) int {
+	f := d.(*flapDetector)
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.seen)
+}
+
+// SyslogSeverity returns the syslog severity NewSyslogText writes for l.
+var SyslogSeverity = syslogSeverity
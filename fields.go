@@ -0,0 +1,72 @@
+package diag
+
+import (
+	"fmt"
+	"strings"
+)
+
+// WithFields returns an Interface that attaches the key/value pairs in kv,
+// such as "request_id", "abc", "method", "GET", to every message issued
+// through it before forwarding it to d. Text sinks receive the fields
+// appended to the message, as in "failed request_id=abc method=GET". Keys are
+// formatted with fmt.Sprint, and values with %v. A trailing key without a
+// value receives the value "<missing>".
+//
+// Fields are additive: WithFields(WithFields(d, "a", 1), "b", 2) carries both
+// a and b. Masks registered on d or on the returned Interface apply to the
+// fields as well, and to any Interface derived from it by WithFields.
+//
+// If d implements FieldsAttacher, it owns the implementation, so that
+// structured sinks such as NewJSON can record each field separately.
+func WithFields(d Interface, kv ...interface{}) Interface {
+	if f, ok := d.(FieldsAttacher); ok {
+		return f.WithFields(kv...)
+	}
+	return newFielded(d, fieldPairs(kv), nil)
+}
+
+// fieldPairs pairs up the keys and values of kv.
+func fieldPairs(kv []interface{}) []jsonField {
+	fields := make([]jsonField, 0, (len(kv)+1)/2)
+	for i := 0; i < len(kv); i += 2 {
+		var v interface{} = "<missing>"
+		if i+1 < len(kv) {
+			v = kv[i+1]
+		}
+		fields = append(fields, jsonField{name: fmt.Sprint(kv[i]), value: v})
+	}
+	return fields
+}
+
+type fielded struct {
+	funnel
+	inner  Interface
+	fields []jsonField
+	parent *fielded
+}
+
+func newFielded(d Interface, fields []jsonField, parent *fielded) *fielded {
+	f := &fielded{inner: d, fields: fields, parent: parent}
+	f.funnel.emit = f.emit
+	return f
+}
+
+// WithFields returns an Interface that attaches kv in addition to the fields
+// of f, and masks the values masked on f.
+func (f *fielded) WithFields(kv ...interface{}) Interface {
+	fields := append(f.fields[:len(f.fields):len(f.fields)], fieldPairs(kv)...)
+	return newFielded(f.inner, fields, f)
+}
+
+func (f *fielded) emit(m Diagnostic) {
+	var sb strings.Builder
+	sb.WriteString(m.Msg)
+	for _, kv := range f.fields {
+		fmt.Fprintf(&sb, " %s=%v", kv.name, kv.value)
+	}
+	m.Msg = sb.String()
+	for p := f; p != nil; p = p.parent {
+		m.Msg = mask(p).replace(m.Msg)
+	}
+	forward(f.inner, m)
+}
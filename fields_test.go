@@ -0,0 +1,60 @@
+package diag_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mutility/diag"
+)
+
+func TestWithFields(t *testing.T) {
+	sb := &strings.Builder{}
+	inner := diag.NewWriter(sb)
+	d := diag.WithFields(diag.WithFields(inner, "request_id", "abc"), "method", "GET", 1)
+	diag.Warningf(d, "slow %s", "request")
+	diag.ErrorAt(d, "h.go", 1, 2, "failed")
+
+	masked := diag.WithFields(inner, "token", "secret")
+	diag.MaskValue(masked, "secret")
+	t.Cleanup(func() { diag.Forget(masked) })
+	diag.Print(masked, "masked")
+
+	want := "slow request request_id=abc method=GET 1=<missing>\n" +
+		"[h.go:1.2] failed request_id=abc method=GET 1=<missing>\n" +
+		"masked token=***\n"
+	if got := sb.String(); got != want {
+		t.Errorf("got %q; want %q", got, want)
+	}
+}
+
+func TestJSONFields(t *testing.T) {
+	sb := &strings.Builder{}
+	j := diag.NewJSON(sb)
+	d := diag.WithFields(diag.WithCorrelationID(j, "req"), "user", 42, "odd")
+	diag.ErrorAt(diag.WithFields(d, "extra", true), "a.go", 1, 2, "failed")
+	diag.Print(j, "no fields")
+
+	want := `{"severity":"error","file":"a.go","line":1,"col":2,"msg":"failed","correlation_id":"req","user":42,"odd":"\u003cmissing\u003e","extra":true}` + "\n" +
+		`{"severity":"print","msg":"no fields"}` + "\n"
+	if got := sb.String(); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestWithFieldsDerivedMasks(t *testing.T) {
+	for name, newD := range map[string]func(*strings.Builder) diag.Interface{
+		"text": func(sb *strings.Builder) diag.Interface { return diag.NewWriter(sb) },
+		"json": func(sb *strings.Builder) diag.Interface { return diag.NewJSON(sb) },
+	} {
+		t.Run(name, func(t *testing.T) {
+			sb := &strings.Builder{}
+			d := diag.WithFields(newD(sb), "a", "secret")
+			diag.MaskValue(d, "secret")
+			t.Cleanup(func() { diag.Forget(d) })
+			diag.Print(diag.WithFields(d, "b", "secret"), "secret")
+			if got := sb.String(); strings.Contains(got, "secret") {
+				t.Errorf("got %q; want masked", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package diag
+
+import "sync"
+
+// FileReport holds diagnostics until Flush reports them grouped by file. See
+// NewFileReport.
+type FileReport struct {
+	inner Interface
+
+	mu    sync.Mutex
+	diags []Diagnostic
+}
+
+// NewFileReport creates an Interface that holds all diagnostics until Flush
+// is called, which suits compiler-style reports at the end of a run.
+func NewFileReport(inner Interface) (*FileReport, Interface) {
+	fr := &FileReport{inner: inner}
+	return fr, &funnel{emit: fr.record}
+}
+
+func (fr *FileReport) record(m Diagnostic) {
+	fr.mu.Lock()
+	defer fr.mu.Unlock()
+	fr.diags = append(fr.diags, m)
+}
+
+// Flush emits the diagnostics held so far to inner, and forgets them. Each
+// file's diagnostics are output in a Group titled with the file name, sorted
+// by line and column, and the files are ordered by name. Diagnostics without
+// a file follow in a Group titled "general", in the order they were emitted.
+func (fr *FileReport) Flush() {
+	if h := thelper(fr.inner); h != nil {
+		h()
+	}
+	fr.mu.Lock()
+	diags := fr.diags
+	fr.diags = nil
+	fr.mu.Unlock()
+
+	sortByLocation(diags)
+	for len(diags) > 0 {
+		file, n := diags[0].File, 1
+		for n < len(diags) && diags[n].File == file {
+			n++
+		}
+		title := file
+		if title == "" {
+			title = "general"
+		}
+		Group(fr.inner, title, func(g Interface) {
+			for _, m := range diags[:n] {
+				forward(g, m)
+			}
+		})
+		diags = diags[n:]
+	}
+}
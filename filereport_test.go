@@ -0,0 +1,42 @@
+package diag_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mutility/diag"
+)
+
+func TestFileReport(t *testing.T) {
+	sb := &strings.Builder{}
+	fr, d := diag.NewFileReport(diag.NewWriter(sb))
+	diag.ErrorAt(d, "b.go", 7, 1, "b7")
+	diag.Warning(d, "unlocated")
+	diag.WarningAt(d, "a.go", 3, 2, "a3")
+	diag.ErrorAtf(d, "b.go", 2, 5, "b%d", 2)
+	diag.WarningAt(d, "a.go", 1, 9, "a1")
+	diag.Error(d, "also unlocated")
+
+	if sb.Len() != 0 {
+		t.Fatalf("output before Flush: %q", sb.String())
+	}
+	fr.Flush()
+	want := "a.go:\n" +
+		"[a.go:1.9]   a1\n" +
+		"[a.go:3.2]   a3\n" +
+		"b.go:\n" +
+		"[b.go:2.5]   b2\n" +
+		"[b.go:7.1]   b7\n" +
+		"general:\n" +
+		"  unlocated\n" +
+		"  also unlocated\n"
+	if got := sb.String(); got != want {
+		t.Errorf("got %q; want %q", got, want)
+	}
+
+	sb.Reset()
+	fr.Flush()
+	if got := sb.String(); got != "" {
+		t.Errorf("second flush: got %q; want nothing", got)
+	}
+}
@@ -0,0 +1,62 @@
+package diag
+
+import (
+	"sync"
+	"time"
+)
+
+// NewFlapDetector creates an Interface that forwards to inner, except that a
+// warning is emitted as an error if it is the count'th identical warning
+// within window. The count then starts over. Warnings are identical if they
+// have the same message and location. This separates flapping conditions from
+// isolated ones. Times come from the Clock passed to WithClock, if any, and
+// otherwise from the system clock.
+func NewFlapDetector(inner Interface, count int, window time.Duration, opts ...Option) Interface {
+	f := &flapDetector{
+		inner:  inner,
+		count:  count,
+		window: window,
+		opts:   newOptions(opts),
+		seen:   make(map[Diagnostic][]time.Time),
+	}
+	f.funnel.emit = f.emit
+	return f
+}
+
+type flapDetector struct {
+	funnel
+	inner  Interface
+	count  int
+	window time.Duration
+	opts   options
+
+	mu   sync.Mutex
+	seen map[Diagnostic][]time.Time
+}
+
+func (f *flapDetector) emit(m Diagnostic) {
+	if m.Level == LevelWarning {
+		now := f.opts.now()
+		f.mu.Lock()
+		// Forget warnings whose latest occurrence has left the window, so
+		// that isolated warnings do not accumulate.
+		for k, times := range f.seen {
+			if now.Sub(times[len(times)-1]) >= f.window {
+				delete(f.seen, k)
+			}
+		}
+		recent := f.seen[m]
+		for len(recent) > 0 && now.Sub(recent[0]) >= f.window {
+			recent = recent[1:]
+		}
+		recent = append(recent, now)
+		if len(recent) >= f.count {
+			delete(f.seen, m)
+			m.Level = LevelError
+		} else {
+			f.seen[m] = recent
+		}
+		f.mu.Unlock()
+	}
+	forward(f.inner, m)
+}
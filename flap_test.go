@@ -0,0 +1,47 @@
+package diag_test
+
+import (
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mutility/diag"
+)
+
+func TestFlapDetector(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		interval time.Duration
+		want     string
+	}{
+		{"rapid", time.Second, "W flap\nW flap\nE flap\nW flap\nW flap\n"},
+		{"slow", 6 * time.Second, "W flap\nW flap\nW flap\nW flap\nW flap\n"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := diag.NewFakeClock(time.Date(2021, 3, 4, 5, 6, 7, 0, time.UTC))
+			sb := &strings.Builder{}
+			w := diag.NewWriters(diag.NewPrefixed(sb, "E"), diag.NewPrefixed(sb, "W"), sb)
+			d := diag.NewFlapDetector(w, 3, 10*time.Second, diag.WithClock(clock))
+			for i := 0; i < 5; i++ {
+				diag.Warning(d, "flap")
+				clock.Advance(tt.interval)
+			}
+			if got := sb.String(); got != tt.want {
+				t.Errorf("got %q; want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFlapDetectorPrunes(t *testing.T) {
+	clock := diag.NewFakeClock(time.Date(2021, 3, 4, 5, 6, 7, 0, time.UTC))
+	d := diag.NewFlapDetector(diag.NewWriters(io.Discard, io.Discard, io.Discard), 3, 10*time.Second, diag.WithClock(clock))
+	for i := 0; i < 100; i++ {
+		diag.Warningf(d, "isolated %d", i)
+		clock.Advance(time.Second)
+	}
+	if got := diag.FlapTracked(d); got != 10 {
+		t.Errorf("tracked %d warnings; want 10", got)
+	}
+}
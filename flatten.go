@@ -0,0 +1,53 @@
+package diag
+
+// NewFlatten creates an Interface that renders groups by indenting their
+// messages and forwarding them to inner, even if inner implements Grouper.
+// Nested groups indent further, so that text copied from the output keeps
+// its hierarchy regardless of inner's native grouping. Each level is indented
+// by two spaces unless overridden by WithIndent. Within a group, a message's
+// location is formatted into its text after the indentation, with inner's
+// AtFormatter if it implements one, so located messages keep their nesting.
+func NewFlatten(inner Interface, opts ...Option) Interface {
+	o := newOptions(opts)
+	indent := "  "
+	if o.indent != nil {
+		indent = *o.indent
+	}
+	return newFlatten(inner, inner, indent, "")
+}
+
+type flatten struct {
+	funnel
+	inner  Interface // formats locations within groups
+	parent Interface
+	indent string
+	prefix string
+}
+
+func newFlatten(inner, parent Interface, indent, prefix string) *flatten {
+	f := &flatten{inner: inner, parent: parent, indent: indent, prefix: prefix}
+	f.funnel.emit = f.emit
+	return f
+}
+
+func (f *flatten) emit(m Diagnostic) {
+	if f.prefix != "" {
+		if loc := formatAt(f.inner, m.File, m.Line, m.Col); loc != "" {
+			m.Msg = loc + " " + m.Msg
+		}
+		m.File, m.Line, m.Col = "", 0, 0
+	}
+	m.Msg = f.prefix + m.Msg
+	forward(f.parent, m)
+}
+
+// Group prints the title and runs fn against an Interface that indents one
+// level further. Messages are forwarded through f, so masks registered on f
+// apply within the group.
+func (f *flatten) Group(title string, fn func(Interface)) {
+	if h := thelper(f.parent); h != nil {
+		h()
+	}
+	Printf(f, "%s:", title)
+	fn(newFlatten(f.inner, f, f.indent, f.indent))
+}
@@ -0,0 +1,70 @@
+package diag_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mutility/diag"
+)
+
+// nativeGroup implements Grouper with output that differs from the fallback.
+type nativeGroup struct{ diag.Interface }
+
+func (g nativeGroup) Group(title string, fn func(diag.Interface)) {
+	diag.Print(g.Interface, "::group::"+title)
+	fn(g)
+	diag.Print(g.Interface, "::endgroup::")
+}
+
+func TestFlatten(t *testing.T) {
+	emit := func(d diag.Interface) {
+		diag.Print(d, "top")
+		diag.Group(d, "outer", func(d diag.Interface) {
+			diag.Warning(d, "one")
+			diag.Group(d, "inner", func(d diag.Interface) {
+				diag.Errorf(d, "%s", "two")
+				diag.ErrorAt(d, "fn.go", 1, 2, "three")
+			})
+			diag.Print(d, "four")
+		})
+	}
+	for _, tt := range []struct {
+		name   string
+		inner  func(*strings.Builder) diag.Interface
+		opts   []diag.Option
+		indent string
+	}{
+		{"writer", func(sb *strings.Builder) diag.Interface { return diag.NewWriter(sb) }, nil, "  "},
+		{"grouper", func(sb *strings.Builder) diag.Interface { return nativeGroup{diag.NewWriter(sb)} }, nil, "  "},
+		{"indent", func(sb *strings.Builder) diag.Interface { return nativeGroup{diag.NewWriter(sb)} }, []diag.Option{diag.WithIndent("| ")}, "| "},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			sb := &strings.Builder{}
+			emit(diag.NewFlatten(tt.inner(sb), tt.opts...))
+			i := tt.indent
+			want := "top\n" +
+				"outer:\n" +
+				i + "one\n" +
+				i + "inner:\n" +
+				i + i + "two\n" +
+				i + i + "[fn.go:1.2] three\n" +
+				i + "four\n"
+			if got := sb.String(); got != want {
+				t.Errorf("got %q; want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestFlattenMask(t *testing.T) {
+	sb := &strings.Builder{}
+	d := diag.NewFlatten(diag.NewWriter(sb))
+	diag.MaskValue(d, "secret")
+	t.Cleanup(func() { diag.Forget(d) })
+	diag.Group(d, "secret group", func(d diag.Interface) {
+		diag.Print(d, "a secret")
+	})
+	if got, want := sb.String(), "*** group:\n  a ***\n"; got != want {
+		t.Errorf("got %q; want %q", got, want)
+	}
+}
@@ -0,0 +1,150 @@
+package diag
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Diagnostic is a single rendered message along with its severity and
+// location. File is empty, and Line and Col are zero, for messages that were
+// not issued through an ...At or ...Atf variant.
+type Diagnostic struct {
+	Level Level  `json:"severity"`
+	File  string `json:"file,omitempty"`
+	Line  int    `json:"line,omitempty"`
+	Col   int    `json:"col,omitempty"`
+	Msg   string `json:"msg"`
+}
+
+// String renders m as its level, location, and message, such as
+// "error: [file.go:10.3] message".
+func (m Diagnostic) String() string {
+	if loc := FormatAt(m.File, m.Line, m.Col); loc != "" {
+		return m.Level.String() + ": " + loc + " " + m.Msg
+	}
+	return m.Level.String() + ": " + m.Msg
+}
+
+// funnel implements the output methods of FullInterface by rendering each
+// call into a Diagnostic and passing it to emit. It deliberately implements
+// neither Grouper nor ValueMasker, so diag's fallbacks handle those.
+type funnel struct {
+	emit func(Diagnostic)
+}
+
+func (f *funnel) Debug(a ...interface{}) {
+	f.emit(Diagnostic{Level: LevelDebug, Msg: sprintln(a)})
+}
+
+func (f *funnel) Debugf(format string, a ...interface{}) {
+	f.emit(Diagnostic{Level: LevelDebug, Msg: fmt.Sprintf(format, a...)})
+}
+
+func (f *funnel) DebugAt(file string, line, col int, a ...interface{}) {
+	f.emit(Diagnostic{LevelDebug, file, line, col, sprintln(a)})
+}
+
+func (f *funnel) DebugAtf(file string, line, col int, format string, a ...interface{}) {
+	f.emit(Diagnostic{LevelDebug, file, line, col, fmt.Sprintf(format, a...)})
+}
+
+func (f *funnel) Info(a ...interface{}) {
+	f.emit(Diagnostic{Level: LevelInfo, Msg: sprintln(a)})
+}
+
+func (f *funnel) Infof(format string, a ...interface{}) {
+	f.emit(Diagnostic{Level: LevelInfo, Msg: fmt.Sprintf(format, a...)})
+}
+
+func (f *funnel) InfoAt(file string, line, col int, a ...interface{}) {
+	f.emit(Diagnostic{LevelInfo, file, line, col, sprintln(a)})
+}
+
+func (f *funnel) InfoAtf(file string, line, col int, format string, a ...interface{}) {
+	f.emit(Diagnostic{LevelInfo, file, line, col, fmt.Sprintf(format, a...)})
+}
+
+func (f *funnel) Print(a ...interface{}) {
+	f.emit(Diagnostic{Level: LevelPrint, Msg: sprintln(a)})
+}
+
+func (f *funnel) Printf(format string, a ...interface{}) {
+	f.emit(Diagnostic{Level: LevelPrint, Msg: fmt.Sprintf(format, a...)})
+}
+
+func (f *funnel) PrintAt(file string, line, col int, a ...interface{}) {
+	f.emit(Diagnostic{LevelPrint, file, line, col, sprintln(a)})
+}
+
+func (f *funnel) PrintAtf(file string, line, col int, format string, a ...interface{}) {
+	f.emit(Diagnostic{LevelPrint, file, line, col, fmt.Sprintf(format, a...)})
+}
+
+func (f *funnel) Warning(a ...interface{}) {
+	f.emit(Diagnostic{Level: LevelWarning, Msg: sprintln(a)})
+}
+
+func (f *funnel) Warningf(format string, a ...interface{}) {
+	f.emit(Diagnostic{Level: LevelWarning, Msg: fmt.Sprintf(format, a...)})
+}
+
+func (f *funnel) WarningAt(file string, line, col int, a ...interface{}) {
+	f.emit(Diagnostic{LevelWarning, file, line, col, sprintln(a)})
+}
+
+func (f *funnel) WarningAtf(file string, line, col int, format string, a ...interface{}) {
+	f.emit(Diagnostic{LevelWarning, file, line, col, fmt.Sprintf(format, a...)})
+}
+
+func (f *funnel) Error(a ...interface{}) {
+	f.emit(Diagnostic{Level: LevelError, Msg: sprintln(a)})
+}
+
+func (f *funnel) Errorf(format string, a ...interface{}) {
+	f.emit(Diagnostic{Level: LevelError, Msg: fmt.Sprintf(format, a...)})
+}
+
+func (f *funnel) ErrorAt(file string, line, col int, a ...interface{}) {
+	f.emit(Diagnostic{LevelError, file, line, col, sprintln(a)})
+}
+
+func (f *funnel) ErrorAtf(file string, line, col int, format string, a ...interface{}) {
+	f.emit(Diagnostic{LevelError, file, line, col, fmt.Sprintf(format, a...)})
+}
+
+// sprintln renders a the way the writers in this package do, without the
+// trailing newline.
+func sprintln(a []interface{}) string {
+	return strings.TrimSuffix(fmt.Sprintln(a...), "\n")
+}
+
+// forward reissues m on d through the public functions, so that d's own
+// capabilities, fallbacks, and masking apply.
+func forward(d Interface, m Diagnostic) {
+	if h := thelper(d); h != nil {
+		h()
+	}
+	located := m.File != "" || m.Line != 0 || m.Col != 0
+	switch {
+	case m.Level == LevelDebug && located:
+		DebugAt(d, m.File, m.Line, m.Col, m.Msg)
+	case m.Level == LevelDebug:
+		Debug(d, m.Msg)
+	case m.Level == LevelInfo && located:
+		InfoAt(d, m.File, m.Line, m.Col, m.Msg)
+	case m.Level == LevelInfo:
+		Info(d, m.Msg)
+	case m.Level == LevelPrint && located:
+		PrintAt(d, m.File, m.Line, m.Col, m.Msg)
+	case m.Level == LevelPrint:
+		Print(d, m.Msg)
+	case m.Level == LevelWarning && located:
+		WarningAt(d, m.File, m.Line, m.Col, m.Msg)
+	case m.Level == LevelWarning:
+		Warning(d, m.Msg)
+	case located:
+		ErrorAt(d, m.File, m.Line, m.Col, m.Msg)
+	default:
+		Error(d, m.Msg)
+	}
+}
@@ -0,0 +1,116 @@
+package diag
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+// NewGitHubActions creates an Interface that writes each message to w as a
+// GitHub Actions workflow command, so that errors and warnings show up as
+// annotations:
+//
+//     ::error file=main.go,line=10,col=3::message
+//
+// Info messages use ::notice::, Debug messages use ::debug::, and Print
+// messages are written as plain lines, with a zero-width space before any
+// line starting with "::" so it is not run as a command. Locations omit any
+// parts that are zero or empty.
+//
+// Groups are written as ::group:: and ::endgroup:: commands. Since GitHub
+// Actions does not nest groups, the titles of nested groups are written as
+// plain lines like Print messages, and their messages as part of the
+// outermost group.
+//
+// Write errors are passed to the handler supplied by WithErrorHandler.
+func NewGitHubActions(w io.Writer, opts ...Option) Interface {
+	g := &githubWriter{w: w, opts: newOptions(opts)}
+	g.funnel.emit = g.emit
+	return g
+}
+
+type githubWriter struct {
+	funnel
+	mu    sync.Mutex
+	w     io.Writer
+	opts  options
+	depth int // of nested groups
+}
+
+var (
+	githubData     = strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A")
+	githubProperty = strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C")
+)
+
+func (g *githubWriter) emit(m Diagnostic) {
+	var line string
+	switch m.Level {
+	case LevelPrint:
+		line = githubPlain(m.Msg) + "\n"
+	case LevelDebug:
+		line = "::debug::" + githubData.Replace(m.Msg) + "\n"
+	default:
+		var props []string
+		if m.File != "" {
+			props = append(props, "file="+githubProperty.Replace(m.File))
+		}
+		if m.Line != 0 {
+			props = append(props, fmt.Sprint("line=", m.Line))
+		}
+		if m.Col != 0 {
+			props = append(props, fmt.Sprint("col=", m.Col))
+		}
+		cmd := "::" + m.Level.String()
+		if m.Level == LevelInfo {
+			cmd = "::notice"
+		}
+		if len(props) > 0 {
+			cmd += " " + strings.Join(props, ",")
+		}
+		line = cmd + "::" + githubData.Replace(m.Msg) + "\n"
+	}
+	g.write(line)
+}
+
+// githubPlain returns s with a zero-width space inserted before any line that
+// would otherwise start with "::", so that logged text cannot issue workflow
+// commands of its own.
+func githubPlain(s string) string {
+	lines := strings.Split(s, "\n")
+	for i, l := range lines {
+		if strings.HasPrefix(strings.TrimLeft(l, " \t\r"), "::") {
+			lines[i] = "\u200b" + l
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (g *githubWriter) write(line string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	_, err := io.WriteString(g.w, line)
+	g.opts.error(err)
+}
+
+func (g *githubWriter) Group(title string, fn func(Interface)) {
+	title = mask(g).replace(title)
+	g.mu.Lock()
+	g.depth++
+	outer := g.depth == 1
+	g.mu.Unlock()
+	defer func() {
+		g.mu.Lock()
+		g.depth--
+		g.mu.Unlock()
+	}()
+
+	if !outer {
+		g.write(githubPlain(title+":") + "\n")
+		fn(g)
+		return
+	}
+	g.write("::group::" + githubData.Replace(title) + "\n")
+	defer g.write("::endgroup::\n")
+	fn(g)
+}
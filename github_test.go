@@ -0,0 +1,59 @@
+package diag_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mutility/diag"
+)
+
+func TestGitHubActions(t *testing.T) {
+	tests := []struct {
+		name string
+		emit func(diag.Interface)
+		want string
+	}{
+		{"debug", func(d diag.Interface) { diag.Debug(d, "dbg") }, "::debug::dbg\n"},
+		{"info", func(d diag.Interface) { diag.Info(d, "note") }, "::notice::note\n"},
+		{"infoatf", func(d diag.Interface) { diag.InfoAtf(d, "a.go", 1, 2, "note %d", 1) }, "::notice file=a.go,line=1,col=2::note 1\n"},
+		{"print", func(d diag.Interface) { diag.Print(d, "plain") }, "plain\n"},
+		{"printcommand", func(d diag.Interface) { diag.Print(d, "::error::injected\n  ::stop-commands::x\nok::") }, "\u200b::error::injected\n\u200b  ::stop-commands::x\nok::\n"},
+		{"warning", func(d diag.Interface) { diag.Warning(d, "warn") }, "::warning::warn\n"},
+		{"warningf", func(d diag.Interface) { diag.Warningf(d, "warn %d", 1) }, "::warning::warn 1\n"},
+		{"warningat", func(d diag.Interface) { diag.WarningAt(d, "a.go", 1, 2, "warn") }, "::warning file=a.go,line=1,col=2::warn\n"},
+		{"warningatf", func(d diag.Interface) { diag.WarningAtf(d, "a.go", 1, 0, "warn %d", 1) }, "::warning file=a.go,line=1::warn 1\n"},
+		{"error", func(d diag.Interface) { diag.Error(d, "err") }, "::error::err\n"},
+		{"errorf", func(d diag.Interface) { diag.Errorf(d, "err %d", 1) }, "::error::err 1\n"},
+		{"errorat", func(d diag.Interface) { diag.ErrorAt(d, "a.go", 3, 4, "err") }, "::error file=a.go,line=3,col=4::err\n"},
+		{"erroratf", func(d diag.Interface) { diag.ErrorAtf(d, "a,b:c.go", 3, 4, "err %d", 1) }, "::error file=a%2Cb%3Ac.go,line=3,col=4::err 1\n"},
+		{"multiline", func(d diag.Interface) { diag.Error(d, "50%\nfailed") }, "::error::50%25%0Afailed\n"},
+		{"crlf", func(d diag.Interface) { diag.WarningAt(d, "a\nb.go", 1, 0, "one\r\ntwo\n") }, "::warning file=a%0Ab.go,line=1::one%0D%0Atwo%0A\n"},
+		{"commas", func(d diag.Interface) { diag.Warning(d, "a, b: c") }, "::warning::a, b: c\n"},
+		{"group", func(d diag.Interface) {
+			diag.Group(d, "outer\n50%", func(d diag.Interface) {
+				diag.Error(d, "e")
+				diag.Group(d, "inner", func(d diag.Interface) { diag.Print(d, "p") })
+			})
+			diag.Print(d, "after")
+		}, "::group::outer%0A50%25\n::error::e\ninner:\np\n::endgroup::\nafter\n"},
+		{"nestedcommand", func(d diag.Interface) {
+			diag.Group(d, "outer", func(d diag.Interface) {
+				diag.Group(d, "::error::inner", func(diag.Interface) {})
+			})
+		}, "::group::outer\n\u200b::error::inner:\n::endgroup::\n"},
+		{"masked", func(d diag.Interface) {
+			diag.MaskValue(d, "hunter2")
+			t.Cleanup(func() { diag.Forget(d) })
+			diag.ErrorAt(d, "a.go", 1, 1, "bad password hunter2")
+		}, "::error file=a.go,line=1,col=1::bad password ***\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sb := &strings.Builder{}
+			tt.emit(diag.NewGitHubActions(sb))
+			if got := sb.String(); got != tt.want {
+				t.Errorf("got %q; want %q", got, tt.want)
+			}
+		})
+	}
+}
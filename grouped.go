@@ -1,10 +1,15 @@
 package diag
 
-import "context"
+import (
+	"context"
+	"sort"
+	"sync"
+)
 
 // Group begins a grouped section of output. If d implements Grouper, it
 // owns the implementation and its behavior. If not, diag will indent lines
-// output during the call to fn.
+// output during the call to fn. Groups nested within such a group indent
+// their title to the outer group's depth, and their lines one level further.
 //
 // It is not well-defined what happens if methods on d are called during fn.
 func Group(d Interface, title string, fn func(Interface)) {
@@ -36,20 +41,139 @@ func GroupContext(d Context, title string, fn func(Context)) {
 	}
 }
 
+// GroupIf begins a grouped section of output like Group if cond is true.
+// Otherwise it runs fn against d directly, without a title or indentation.
+func GroupIf(d Interface, cond bool, title string, fn func(Interface)) {
+	if h := thelper(d); h != nil {
+		h()
+	}
+	if cond {
+		Group(d, title, fn)
+	} else {
+		fn(d)
+	}
+}
+
+// GroupNonEmpty begins a grouped section of output like Group, except that
+// if d does not implement Grouper, the title is only output along with the
+// first line output during fn. Thus a group with nothing to report produces
+// no output at all. If d implements Grouper, it owns the behavior as for
+// Group.
+func GroupNonEmpty(d Interface, title string, fn func(Interface)) {
+	if h := thelper(d); h != nil {
+		h()
+	}
+	if g, ok := d.(Grouper); ok {
+		g.Group(title, fn)
+		return
+	}
+	var once sync.Once
+	g := &grouped{d}
+	fn(&funnel{emit: func(m Diagnostic) {
+		once.Do(func() { Printf(d, "%s:", title) })
+		forward(g, m)
+	}})
+}
+
+// GroupE begins a grouped section of output like Group, and returns the
+// error returned by fn. The group is closed as usual whether or not fn
+// returns an error.
+func GroupE(d Interface, title string, fn func(Interface) error) error {
+	if h := thelper(d); h != nil {
+		h()
+	}
+	var err error
+	Group(d, title, func(g Interface) { err = fn(g) })
+	return err
+}
+
+// GroupContextE begins a grouped section of output like GroupContext, and
+// returns the error returned by fn. The group is closed as usual whether or
+// not fn returns an error.
+func GroupContextE(d Context, title string, fn func(Context) error) error {
+	if h := thelper(d); h != nil {
+		h()
+	}
+	var err error
+	GroupContext(d, title, func(g Context) { err = fn(g) })
+	return err
+}
+
+// GroupSorted begins a grouped section of output like Group, but holds the
+// messages output during fn, and outputs them once fn returns, sorted by file,
+// line, and column. Messages without a file follow those with one, in the
+// order they were output. Since all messages are held, nested groups are
+// flattened into their indented lines, which are sorted like any other.
+func GroupSorted(d Interface, title string, fn func(Interface)) {
+	if h := thelper(d); h != nil {
+		h()
+	}
+	var held []Diagnostic
+	fn(&funnel{emit: func(m Diagnostic) { held = append(held, m) }})
+	sortByLocation(held)
+	Group(d, title, func(g Interface) {
+		for _, m := range held {
+			forward(g, m)
+		}
+	})
+}
+
+// sortByLocation stably sorts diags by file, line, and column, placing those
+// without a file last.
+func sortByLocation(diags []Diagnostic) {
+	sort.SliceStable(diags, func(i, j int) bool {
+		a, b := diags[i], diags[j]
+		switch {
+		case a.File == "" || b.File == "":
+			return a.File != "" && b.File == ""
+		case a.File != b.File:
+			return a.File < b.File
+		case a.Line != b.Line:
+			return a.Line < b.Line
+		}
+		return a.Col < b.Col
+	})
+}
+
 type groupedctx struct {
 	grouped
 	context.Context
 }
 
+// grouped indents each message by two spaces. Arguments are rendered with
+// sprintln before indenting, so that the indent does not depend on whether
+// d joins arguments like fmt.Sprint or fmt.Sprintln.
 type grouped struct {
 	d Interface
 }
 
+// premasked is text whose arguments were already masked. It is not a string,
+// so Args passes it through rather than masking the joined text again, which
+// would match values spanning arguments or rendered from non-strings.
+type premasked string
+
+// indent masks each of a like the public functions do for d, and for the
+// Interfaces of any groups d is nested in, then joins and indents them.
+func (g *grouped) indent(a []interface{}) premasked {
+	for d := g.d; d != nil; {
+		a = mask(d).Args(a)
+		switch p := d.(type) {
+		case *grouped:
+			d = p.d
+		case *groupedctx:
+			d = p.grouped.d
+		default:
+			d = nil
+		}
+	}
+	return premasked("  " + sprintln(a))
+}
+
 func (g *grouped) Debug(a ...interface{}) {
 	if h := thelper(g.d); h != nil {
 		h()
 	}
-	Debug(g.d, append([]interface{}{" "}, a...)...)
+	Debug(g.d, g.indent(a))
 }
 
 func (g *grouped) Debugf(format string, a ...interface{}) {
@@ -59,11 +183,53 @@ func (g *grouped) Debugf(format string, a ...interface{}) {
 	Debugf(g.d, "  "+format, a...)
 }
 
+func (g *grouped) DebugAt(file string, line, col int, a ...interface{}) {
+	if h := thelper(g.d); h != nil {
+		h()
+	}
+	DebugAt(g.d, file, line, col, g.indent(a))
+}
+
+func (g *grouped) DebugAtf(file string, line, col int, format string, a ...interface{}) {
+	if h := thelper(g.d); h != nil {
+		h()
+	}
+	DebugAtf(g.d, file, line, col, "  "+format, a...)
+}
+
+func (g *grouped) Info(a ...interface{}) {
+	if h := thelper(g.d); h != nil {
+		h()
+	}
+	Info(g.d, g.indent(a))
+}
+
+func (g *grouped) Infof(format string, a ...interface{}) {
+	if h := thelper(g.d); h != nil {
+		h()
+	}
+	Infof(g.d, "  "+format, a...)
+}
+
+func (g *grouped) InfoAt(file string, line, col int, a ...interface{}) {
+	if h := thelper(g.d); h != nil {
+		h()
+	}
+	InfoAt(g.d, file, line, col, g.indent(a))
+}
+
+func (g *grouped) InfoAtf(file string, line, col int, format string, a ...interface{}) {
+	if h := thelper(g.d); h != nil {
+		h()
+	}
+	InfoAtf(g.d, file, line, col, "  "+format, a...)
+}
+
 func (g *grouped) Print(a ...interface{}) {
 	if h := thelper(g.d); h != nil {
 		h()
 	}
-	Print(g.d, append([]interface{}{" "}, a...)...)
+	Print(g.d, g.indent(a))
 }
 
 func (g *grouped) Printf(format string, a ...interface{}) {
@@ -73,11 +239,25 @@ func (g *grouped) Printf(format string, a ...interface{}) {
 	Printf(g.d, "  "+format, a...)
 }
 
+func (g *grouped) PrintAt(file string, line, col int, a ...interface{}) {
+	if h := thelper(g.d); h != nil {
+		h()
+	}
+	PrintAt(g.d, file, line, col, g.indent(a))
+}
+
+func (g *grouped) PrintAtf(file string, line, col int, format string, a ...interface{}) {
+	if h := thelper(g.d); h != nil {
+		h()
+	}
+	PrintAtf(g.d, file, line, col, "  "+format, a...)
+}
+
 func (g *grouped) Warning(a ...interface{}) {
 	if h := thelper(g.d); h != nil {
 		h()
 	}
-	Warning(g.d, append([]interface{}{" "}, a...)...)
+	Warning(g.d, g.indent(a))
 }
 
 func (g *grouped) Warningf(format string, a ...interface{}) {
@@ -91,7 +271,7 @@ func (g *grouped) WarningAt(file string, line, col int, a ...interface{}) {
 	if h := thelper(g.d); h != nil {
 		h()
 	}
-	WarningAt(g.d, file, line, col, append([]interface{}{" "}, a...)...)
+	WarningAt(g.d, file, line, col, g.indent(a))
 }
 
 func (g *grouped) WarningAtf(file string, line, col int, format string, a ...interface{}) {
@@ -105,7 +285,7 @@ func (g *grouped) Error(a ...interface{}) {
 	if h := thelper(g.d); h != nil {
 		h()
 	}
-	Error(g.d, append([]interface{}{" "}, a...)...)
+	Error(g.d, g.indent(a))
 }
 
 func (g *grouped) Errorf(format string, a ...interface{}) {
@@ -119,7 +299,7 @@ func (g *grouped) ErrorAt(file string, line, col int, a ...interface{}) {
 	if h := thelper(g.d); h != nil {
 		h()
 	}
-	ErrorAt(g.d, file, line, col, append([]interface{}{" "}, a...)...)
+	ErrorAt(g.d, file, line, col, g.indent(a))
 }
 
 func (g *grouped) ErrorAtf(file string, line, col int, format string, a ...interface{}) {
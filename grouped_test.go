@@ -0,0 +1,198 @@
+package diag_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/mutility/diag"
+)
+
+func TestGroupSorted(t *testing.T) {
+	sb := &strings.Builder{}
+	d := diag.NewWriter(sb)
+	diag.GroupSorted(d, "lint", func(d diag.Interface) {
+		diag.WarningAt(d, "b.go", 1, 1, "b1")
+		diag.Print(d, "first unlocated")
+		diag.ErrorAt(d, "a.go", 10, 0, "a10")
+		diag.ErrorAtf(d, "a.go", 2, 5, "%s", "a2.5")
+		diag.WarningAt(d, "a.go", 2, 1, "a2.1")
+		diag.Warning(d, "second unlocated")
+		diag.ErrorAt(d, "a.go", 2, 1, "a2.1 again")
+	})
+	want := "lint:\n" +
+		"[a.go:2.1]   a2.1\n" +
+		"[a.go:2.1]   a2.1 again\n" +
+		"[a.go:2.5]   a2.5\n" +
+		"[a.go:10]   a10\n" +
+		"[b.go:1.1]   b1\n" +
+		"  first unlocated\n" +
+		"  second unlocated\n"
+	if got := sb.String(); got != want {
+		t.Errorf("got %q; want %q", got, want)
+	}
+}
+
+func TestGroupIf(t *testing.T) {
+	for _, tt := range []struct {
+		cond bool
+		want string
+	}{
+		{true, "title:\n  inside\n"},
+		{false, "inside\n"},
+	} {
+		sb := &strings.Builder{}
+		d := diag.NewWriter(sb)
+		diag.GroupIf(d, tt.cond, "title", func(g diag.Interface) {
+			diag.Print(g, "inside")
+		})
+		if got := sb.String(); got != tt.want {
+			t.Errorf("cond=%v: got %q; want %q", tt.cond, got, tt.want)
+		}
+	}
+}
+
+// sprinter joins arguments like fmt.Sprint rather than fmt.Sprintln.
+type sprinter struct{ sb *strings.Builder }
+
+func (s sprinter) line(a ...interface{}) { s.sb.WriteString(fmt.Sprint(a...) + "\n") }
+
+func (s sprinter) Debug(a ...interface{})   { s.line(a...) }
+func (s sprinter) Print(a ...interface{})   { s.line(a...) }
+func (s sprinter) Warning(a ...interface{}) { s.line(a...) }
+func (s sprinter) Error(a ...interface{})   { s.line(a...) }
+
+func TestGroupIndent(t *testing.T) {
+	for name, newD := range map[string]func(*strings.Builder) diag.Interface{
+		"writer":   func(sb *strings.Builder) diag.Interface { return diag.NewWriterDebug(sb) },
+		"sprinter": func(sb *strings.Builder) diag.Interface { return sprinter{sb} },
+	} {
+		t.Run(name, func(t *testing.T) {
+			sb := &strings.Builder{}
+			diag.Group(newD(sb), "g", func(d diag.Interface) {
+				diag.Debug(d, "debug")
+				diag.Debugf(d, "%s", "debugf")
+				diag.Print(d, "print", 1)
+				diag.Printf(d, "%s", "printf")
+				diag.Warning(d, "warning")
+				diag.Warningf(d, "%s", "warningf")
+				diag.Error(d, "error")
+				diag.Errorf(d, "%s", "errorf")
+			})
+			lines := strings.Split(strings.TrimSuffix(sb.String(), "\n"), "\n")
+			for _, line := range lines[1:] {
+				if !strings.HasPrefix(line, "  ") || strings.HasPrefix(line, "   ") {
+					t.Errorf("margin of %q is not two spaces", line)
+				}
+			}
+		})
+	}
+}
+
+// TestGroupMask verifies arguments are masked alike in and out of groups.
+func TestGroupMask(t *testing.T) {
+	sb := &strings.Builder{}
+	d := diag.NewWriter(sb)
+	diag.MaskValue(d, "1")
+	diag.Print(d, "line", 1, "x1")
+	diag.Group(d, "g", func(g diag.Interface) {
+		diag.Print(g, "line", 1, "x1")
+		diag.Group(g, "h", func(h diag.Interface) {
+			diag.WarningAt(h, "a.go", 2, 0, "line", 1, "x1")
+		})
+	})
+	diag.Forget(d)
+
+	want := "line 1 x***\ng:\n  line 1 x***\n  h:\n[a.go:2]     line 1 x***\n"
+	if got := sb.String(); got != want {
+		t.Errorf("got %q; want %q", got, want)
+	}
+}
+
+func TestGroupNested(t *testing.T) {
+	sb := &strings.Builder{}
+	diag.Group(diag.NewWriter(sb), "outer", func(d diag.Interface) {
+		diag.Print(d, "one")
+		diag.Group(d, "inner", func(d diag.Interface) {
+			diag.Warningf(d, "%s", "two")
+			diag.Group(d, "innermost", func(d diag.Interface) {
+				diag.Error(d, "three")
+			})
+		})
+		diag.Print(d, "four")
+	})
+	want := "outer:\n" +
+		"  one\n" +
+		"  inner:\n" +
+		"    two\n" +
+		"    innermost:\n" +
+		"      three\n" +
+		"  four\n"
+	if got := sb.String(); got != want {
+		t.Errorf("got %q; want %q", got, want)
+	}
+}
+
+func TestGroupE(t *testing.T) {
+	errStep := errors.New("step failed")
+	sb := &strings.Builder{}
+	err := diag.GroupE(diag.NewGitHubActions(sb), "step", func(d diag.Interface) error {
+		diag.Print(d, "working")
+		return errStep
+	})
+	if err != errStep {
+		t.Errorf("GroupE: got %v; want %v", err, errStep)
+	}
+	if got, want := sb.String(), "::group::step\nworking\n::endgroup::\n"; got != want {
+		t.Errorf("GroupE: got %q; want %q", got, want)
+	}
+
+	sb.Reset()
+	ctx := diag.WithContext(context.Background(), diag.NewWriter(sb))
+	err = diag.GroupContextE(ctx, "step", func(d diag.Context) error {
+		diag.Print(d, "working")
+		return nil
+	})
+	if err != nil {
+		t.Errorf("GroupContextE: got %v; want nil", err)
+	}
+	if got, want := sb.String(), "step:\n  working\n"; got != want {
+		t.Errorf("GroupContextE: got %q; want %q", got, want)
+	}
+}
+
+func TestGroupNonEmpty(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		fn   func(diag.Interface)
+		want string
+	}{
+		{"empty", func(diag.Interface) {}, ""},
+		{"nested empty", func(d diag.Interface) {
+			diag.GroupNonEmpty(d, "inner", func(diag.Interface) {})
+		}, ""},
+		{"lines", func(d diag.Interface) {
+			diag.Print(d, "one")
+			diag.Warningf(d, "%s", "two")
+		}, "title:\n  one\n  two\n"},
+		{"nested lines", func(d diag.Interface) {
+			diag.GroupNonEmpty(d, "inner", func(d diag.Interface) { diag.Error(d, "three") })
+		}, "title:\n  inner:\n    three\n"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			sb := &strings.Builder{}
+			diag.GroupNonEmpty(diag.NewWriter(sb), "title", tt.fn)
+			if got := sb.String(); got != tt.want {
+				t.Errorf("got %q; want %q", got, tt.want)
+			}
+		})
+	}
+
+	sb := &strings.Builder{}
+	diag.GroupNonEmpty(nativeGroup{diag.NewWriter(sb)}, "title", func(diag.Interface) {})
+	if got, want := sb.String(), "::group::title\n::endgroup::\n"; got != want {
+		t.Errorf("Grouper: got %q; want %q", got, want)
+	}
+}
@@ -0,0 +1,28 @@
+package diag_test
+
+import (
+	"strings"
+	"sync"
+)
+
+// lockedBuilder is a strings.Builder that is safe for concurrent use.
+type lockedBuilder struct {
+	mu sync.Mutex
+	sb strings.Builder
+}
+
+func (b *lockedBuilder) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.sb.Write(p)
+}
+
+func (b *lockedBuilder) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.sb.String()
+}
+
+func (b *lockedBuilder) lines() int {
+	return strings.Count(b.String(), "\n")
+}
@@ -0,0 +1,63 @@
+package diag
+
+import (
+	"sort"
+	"sync"
+)
+
+// Histogram counts occurrences of each distinct message. See NewHistogram.
+type Histogram struct {
+	mu     sync.Mutex
+	counts map[histogramKey]int
+	order  []histogramKey // first occurrence order, for stable ties
+}
+
+type histogramKey struct {
+	level Level
+	msg   string
+}
+
+// HistogramEntry reports how many times a message was emitted at a level.
+type HistogramEntry struct {
+	Level Level
+	Msg   string
+	Count int
+}
+
+// NewHistogram creates an Interface that forwards everything to inner while
+// counting each distinct pair of level and masked message, ignoring location.
+// This helps identify noisy diagnostics.
+func NewHistogram(inner Interface) (*Histogram, Interface) {
+	h := &Histogram{counts: make(map[histogramKey]int)}
+	return h, &funnel{emit: func(m Diagnostic) {
+		h.record(m)
+		forward(inner, m)
+	}}
+}
+
+func (h *Histogram) record(m Diagnostic) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	k := histogramKey{m.Level, m.Msg}
+	if h.counts[k] == 0 {
+		h.order = append(h.order, k)
+	}
+	h.counts[k]++
+}
+
+// Top returns up to n of the most frequent messages, most frequent first.
+// Messages with equal counts are ordered by their first occurrence.
+func (h *Histogram) Top(n int) []HistogramEntry {
+	h.mu.Lock()
+	top := make([]HistogramEntry, len(h.order))
+	for i, k := range h.order {
+		top[i] = HistogramEntry{k.level, k.msg, h.counts[k]}
+	}
+	h.mu.Unlock()
+
+	sort.SliceStable(top, func(i, j int) bool { return top[i].Count > top[j].Count })
+	if n < len(top) {
+		top = top[:n]
+	}
+	return top
+}
@@ -0,0 +1,36 @@
+package diag_test
+
+import (
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/mutility/diag"
+)
+
+func TestHistogram(t *testing.T) {
+	h, d := diag.NewHistogram(diag.NewWriter(io.Discard))
+	diag.MaskValue(d, "alpha")
+	t.Cleanup(func() { diag.Forget(d) })
+	diag.MaskValue(d, "bravo")
+	diag.Warning(d, "once")
+	for i := 0; i < 3; i++ {
+		diag.ErrorAt(d, "fn.go", i, 0, "thrice")
+	}
+	diag.Print(d, "token alpha")
+	diag.Printf(d, "token %s", "bravo") // the same once masked
+	diag.Debug(d, "token alpha")        // a different level
+
+	got := h.Top(3)
+	want := []diag.HistogramEntry{
+		{Level: diag.LevelError, Msg: "thrice", Count: 3},
+		{Level: diag.LevelPrint, Msg: "token ***", Count: 2},
+		{Level: diag.LevelWarning, Msg: "once", Count: 1},
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("got %v; want %v", got, want)
+	}
+	if got := h.Top(100); len(got) != 4 {
+		t.Errorf("Top(100): got %d entries; want 4", len(got))
+	}
+}
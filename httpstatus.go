@@ -0,0 +1,80 @@
+package diag
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"sync"
+)
+
+// HTTPStatus is an Interface that records diagnostics so that a web handler
+// can report them in its response. See NewHTTPStatus.
+type HTTPStatus struct {
+	funnel
+
+	// Statuses maps a level to the HTTP status reported when it is the most
+	// severe level emitted. If the most severe level is not in the map, the
+	// status is http.StatusOK.
+	Statuses map[Level]int
+
+	mu    sync.Mutex
+	diags []Diagnostic
+	opts  options
+}
+
+// NewHTTPStatus creates an HTTPStatus that reports http.StatusBadRequest if
+// any error is emitted, and http.StatusOK otherwise. WithFieldNames renames
+// the fields of the diagnostics written by WriteProblem.
+func NewHTTPStatus(opts ...Option) *HTTPStatus {
+	h := &HTTPStatus{Statuses: map[Level]int{LevelError: http.StatusBadRequest}, opts: newOptions(opts)}
+	h.funnel.emit = h.record
+	return h
+}
+
+func (h *HTTPStatus) record(m Diagnostic) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.diags = append(h.diags, m)
+}
+
+// Status returns the HTTP status for the diagnostics emitted so far.
+func (h *HTTPStatus) Status() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	max := LevelNone
+	for _, m := range h.diags {
+		if m.Level > max {
+			max = m.Level
+		}
+	}
+	if status, ok := h.Statuses[max]; ok {
+		return status
+	}
+	return http.StatusOK
+}
+
+// WriteProblem writes an RFC 7807 application/problem+json response with the
+// code from Status, listing the emitted diagnostics in a "diagnostics"
+// member. Each diagnostic has the same fields as a line written by NewJSON.
+func (h *HTTPStatus) WriteProblem(w http.ResponseWriter) error {
+	status := h.Status()
+	h.mu.Lock()
+	diags := make([]json.RawMessage, len(h.diags))
+	for i, m := range h.diags {
+		var buf bytes.Buffer
+		buf.WriteByte('{')
+		writeJSONDiagnostic(&buf, &h.opts, m)
+		buf.WriteByte('}')
+		diags[i] = buf.Bytes()
+	}
+	h.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(status)
+	return json.NewEncoder(w).Encode(struct {
+		Type        string            `json:"type"`
+		Title       string            `json:"title"`
+		Status      int               `json:"status"`
+		Diagnostics []json.RawMessage `json:"diagnostics"`
+	}{"about:blank", http.StatusText(status), status, diags})
+}
@@ -0,0 +1,69 @@
+package diag_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mutility/diag"
+)
+
+func TestHTTPStatus(t *testing.T) {
+	h := diag.NewHTTPStatus()
+	diag.Warning(h, "deprecated field")
+	if got := h.Status(); got != http.StatusOK {
+		t.Errorf("after warning: got %d; want %d", got, http.StatusOK)
+	}
+	diag.ErrorAt(h, "body", 1, 7, "unexpected token")
+	if got := h.Status(); got != http.StatusBadRequest {
+		t.Errorf("after error: got %d; want %d", got, http.StatusBadRequest)
+	}
+
+	rec := httptest.NewRecorder()
+	if err := h.WriteProblem(rec); err != nil {
+		t.Fatal(err)
+	}
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("code: got %d; want %d", rec.Code, http.StatusBadRequest)
+	}
+	if got, want := rec.Header().Get("Content-Type"), "application/problem+json"; got != want {
+		t.Errorf("content type: got %q; want %q", got, want)
+	}
+	want := `{"type":"about:blank","title":"Bad Request","status":400,"diagnostics":[` +
+		`{"severity":"warning","msg":"deprecated field"},` +
+		`{"severity":"error","file":"body","line":1,"col":7,"msg":"unexpected token"}]}` + "\n"
+	if got := rec.Body.String(); got != want {
+		t.Errorf("body: got %s; want %s", got, want)
+	}
+}
+
+func TestHTTPStatusFieldNames(t *testing.T) {
+	h := diag.NewHTTPStatus(diag.WithFieldNames(map[string]string{"level": "level", "msg": "message"}))
+	diag.WarningAt(h, "body", 2, 0, "careful")
+	rec := httptest.NewRecorder()
+	if err := h.WriteProblem(rec); err != nil {
+		t.Fatal(err)
+	}
+	want := `{"type":"about:blank","title":"OK","status":200,"diagnostics":[` +
+		`{"level":"warning","file":"body","line":2,"message":"careful"}]}` + "\n"
+	if got := rec.Body.String(); got != want {
+		t.Errorf("body: got %s; want %s", got, want)
+	}
+}
+
+func TestHTTPStatusMapping(t *testing.T) {
+	h := diag.NewHTTPStatus()
+	h.Statuses[diag.LevelError] = http.StatusUnprocessableEntity
+	h.Statuses[diag.LevelWarning] = http.StatusAccepted
+	if got := h.Status(); got != http.StatusOK {
+		t.Errorf("empty: got %d; want %d", got, http.StatusOK)
+	}
+	diag.Warning(h, "w")
+	if got := h.Status(); got != http.StatusAccepted {
+		t.Errorf("warning: got %d; want %d", got, http.StatusAccepted)
+	}
+	diag.Error(h, "e")
+	if got := h.Status(); got != http.StatusUnprocessableEntity {
+		t.Errorf("error: got %d; want %d", got, http.StatusUnprocessableEntity)
+	}
+}
@@ -0,0 +1,36 @@
+package diag
+
+// NewHyperlinked creates an Interface that forwards to inner, wrapping the
+// location prefix of ...At and ...Atf messages in an OSC 8 hyperlink to the
+// URL returned by urlFor, such as a file:// or vscode:// link. The location
+// is formatted with inner's FormatAt method if it implements AtFormatter, or
+// with FormatAt otherwise, and the message is forwarded to inner without a
+// location. Messages without a location, or for which urlFor returns "", are
+// forwarded unchanged.
+//
+// Since the destination of inner's output cannot be inspected, hyperlinks are
+// only added when WithTerminal(true) is passed, such as after checking that
+// the writer inner wraps is a terminal.
+func NewHyperlinked(inner Interface, urlFor func(file string, line, col int) string, opts ...Option) Interface {
+	o := newOptions(opts)
+	h := &hyperlinked{inner, urlFor, o.terminal != nil && *o.terminal}
+	return &funnel{emit: h.emit}
+}
+
+type hyperlinked struct {
+	inner   Interface
+	urlFor  func(file string, line, col int) string
+	enabled bool
+}
+
+func (h *hyperlinked) emit(m Diagnostic) {
+	if h.enabled && m.File != "" {
+		if loc := formatAt(h.inner, m.File, m.Line, m.Col); loc != "" {
+			if url := h.urlFor(m.File, m.Line, m.Col); url != "" {
+				m.Msg = "\x1b]8;;" + url + "\x1b\\" + loc + "\x1b]8;;\x1b\\ " + m.Msg
+				m.File, m.Line, m.Col = "", 0, 0
+			}
+		}
+	}
+	forward(h.inner, m)
+}
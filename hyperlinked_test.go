@@ -0,0 +1,67 @@
+package diag_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/mutility/diag"
+)
+
+func TestHyperlinked(t *testing.T) {
+	urlFor := func(file string, line, col int) string {
+		if file == "nolink.go" {
+			return ""
+		}
+		return fmt.Sprintf("vscode://file/%s:%d:%d", file, line, col)
+	}
+	emit := func(d diag.Interface) {
+		diag.Error(d, "plain")
+		diag.ErrorAt(d, "fn.go", 10, 3, "located")
+		diag.WarningAtf(d, "fn.go", 10, 0, "%s", "formatted")
+		diag.WarningAt(d, "nolink.go", 1, 2, "nolink")
+	}
+
+	t.Run("terminal", func(t *testing.T) {
+		sb := &strings.Builder{}
+		emit(diag.NewHyperlinked(diag.NewWriter(sb), urlFor, diag.WithTerminal(true)))
+		want := "plain\n" +
+			"\x1b]8;;vscode://file/fn.go:10:3\x1b\\[fn.go:10.3]\x1b]8;;\x1b\\ located\n" +
+			"\x1b]8;;vscode://file/fn.go:10:0\x1b\\[fn.go:10]\x1b]8;;\x1b\\ formatted\n" +
+			"[nolink.go:1.2] nolink\n"
+		if got := sb.String(); got != want {
+			t.Errorf("got %q; want %q", got, want)
+		}
+	})
+
+	t.Run("notterminal", func(t *testing.T) {
+		sb := &strings.Builder{}
+		emit(diag.NewHyperlinked(diag.NewWriter(sb), urlFor, diag.WithTerminal(false)))
+		want := "plain\n[fn.go:10.3] located\n[fn.go:10] formatted\n[nolink.go:1.2] nolink\n"
+		if got := sb.String(); got != want {
+			t.Errorf("got %q; want %q", got, want)
+		}
+	})
+
+	t.Run("default", func(t *testing.T) {
+		sb := &strings.Builder{}
+		emit(diag.NewHyperlinked(diag.NewWriter(sb), urlFor))
+		want := "plain\n[fn.go:10.3] located\n[fn.go:10] formatted\n[nolink.go:1.2] nolink\n"
+		if got := sb.String(); got != want {
+			t.Errorf("got %q; want %q", got, want)
+		}
+	})
+
+	t.Run("atformatter", func(t *testing.T) {
+		sb := &strings.Builder{}
+		gnu := func(file string, line, col int) string { return fmt.Sprintf("%s:%d:%d:", file, line, col) }
+		emit(diag.NewHyperlinked(&atFormatted{diag.NewWriter(sb), gnu}, urlFor, diag.WithTerminal(true)))
+		want := "plain\n" +
+			"\x1b]8;;vscode://file/fn.go:10:3\x1b\\fn.go:10:3:\x1b]8;;\x1b\\ located\n" +
+			"\x1b]8;;vscode://file/fn.go:10:0\x1b\\fn.go:10:0:\x1b]8;;\x1b\\ formatted\n" +
+			"nolink.go:1:2: nolink\n"
+		if got := sb.String(); got != want {
+			t.Errorf("got %q; want %q", got, want)
+		}
+	})
+}
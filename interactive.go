@@ -0,0 +1,36 @@
+package diag
+
+import "sync/atomic"
+
+// Interactive is an Interface that lets a caller decide after each error
+// whether to continue. See NewInteractive.
+type Interactive struct {
+	funnel
+
+	inner   Interface
+	onError func(Diagnostic) bool
+	halted  int32
+}
+
+// NewInteractive creates an Interface that forwards to inner and, after
+// forwarding each error, calls onError with it. If onError returns false,
+// the Interactive is halted, which the caller can check with Halted to
+// abort its work. Once halted, onError is not called again. This suits tools
+// that prompt "continue? [y/N]" after each error.
+func NewInteractive(inner Interface, onError func(Diagnostic) bool) *Interactive {
+	in := &Interactive{inner: inner, onError: onError}
+	in.funnel.emit = in.forward
+	return in
+}
+
+func (in *Interactive) forward(m Diagnostic) {
+	forward(in.inner, m)
+	if m.Level == LevelError && !in.Halted() && !in.onError(m) {
+		atomic.StoreInt32(&in.halted, 1)
+	}
+}
+
+// Halted reports whether onError has returned false.
+func (in *Interactive) Halted() bool {
+	return atomic.LoadInt32(&in.halted) != 0
+}
@@ -0,0 +1,44 @@
+package diag_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mutility/diag"
+)
+
+func TestInteractive(t *testing.T) {
+	sb := &strings.Builder{}
+	var asked []diag.Diagnostic
+	d := diag.NewInteractive(diag.NewWriter(sb), func(m diag.Diagnostic) bool {
+		asked = append(asked, m)
+		return len(asked) < 2
+	})
+
+	diag.Warning(d, "not asked")
+	diag.ErrorAt(d, "a.go", 1, 2, "first")
+	if d.Halted() {
+		t.Error("halted after first error")
+	}
+	diag.Error(d, "second")
+	if !d.Halted() {
+		t.Error("not halted after second error")
+	}
+	diag.Error(d, "third")
+
+	want := []diag.Diagnostic{
+		{Level: diag.LevelError, File: "a.go", Line: 1, Col: 2, Msg: "first"},
+		{Level: diag.LevelError, Msg: "second"},
+	}
+	if len(asked) != len(want) {
+		t.Fatalf("asked %v; want %v", asked, want)
+	}
+	for i := range want {
+		if asked[i] != want[i] {
+			t.Errorf("asked[%d] = %v; want %v", i, asked[i], want[i])
+		}
+	}
+	if got, want := sb.String(), "not asked\n[a.go:1.2] first\nsecond\nthird\n"; got != want {
+		t.Errorf("got %q; want %q", got, want)
+	}
+}
@@ -0,0 +1,183 @@
+package diag
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// NewJSON creates an Interface that writes each message to w as a JSON object
+// on its own line, such as:
+//
+//     {"severity":"error","file":"x.go","line":10,"col":3,"msg":"failed"}
+//
+// The file, line, and col fields are omitted when empty or zero. The returned
+// Interface implements CorrelationIDer, recording the id in a
+// "correlation_id" field, and FieldsAttacher, recording each field passed to
+// WithFields under its own key. Fields are masked like message arguments, and
+// errors are recorded as their text. A field whose key would duplicate one of
+// the standard fields is recorded with the key prefixed by "fields.", such as
+// "fields.msg". WithFieldNames renames any of the standard fields.
+//
+// Write errors are passed to the handler supplied by WithErrorHandler.
+func NewJSON(w io.Writer, opts ...Option) Interface {
+	return newJSONSink(&jsonOutput{w: w, opts: newOptions(opts)}, nil, nil)
+}
+
+// NewJSONContext returns a function that creates a Context for ctx whose
+// messages are written to w like NewJSON, along with a field for the value in
+// ctx of each of keys. Fields are named by formatting their key with
+// fmt.Sprint, so keys should be strings or types with a String method. Keys
+// without a value in ctx are omitted. Options apply as for NewJSON.
+func NewJSONContext(w io.Writer, keys []interface{}, opts ...Option) func(context.Context) Context {
+	out := &jsonOutput{w: w, opts: newOptions(opts)}
+	return func(ctx context.Context) Context {
+		var fields []jsonField
+		for _, k := range keys {
+			if v := ctx.Value(k); v != nil {
+				fields = append(fields, jsonField{name: fmt.Sprint(k), value: v})
+			}
+		}
+		jc := &jsonContext{ctx, newJSONSink(out, fields, nil)}
+		jc.self = jc
+		return jc
+	}
+}
+
+// jsonContext is like the result of WithContext, but keeps all methods of the
+// sink so that locations remain structured.
+type jsonContext struct {
+	context.Context
+	*jsonSink
+}
+
+// jsonOutput is shared by a JSON sink and those derived from it.
+type jsonOutput struct {
+	mu   sync.Mutex
+	w    io.Writer
+	opts options
+}
+
+type jsonField struct {
+	name     string
+	value    interface{}
+	standard bool // renamed by WithFieldNames
+}
+
+type jsonSink struct {
+	funnel
+	out    *jsonOutput
+	fields []jsonField
+	parent *jsonSink
+	self   interface{} // the jsonContext wrapping the sink, if any
+}
+
+func newJSONSink(out *jsonOutput, fields []jsonField, parent *jsonSink) *jsonSink {
+	j := &jsonSink{out: out, fields: fields, parent: parent}
+	j.funnel.emit = j.write
+	return j
+}
+
+// WithCorrelationID returns a sink that adds a "correlation_id" field with the
+// value id to each message, in addition to any fields of j.
+func (j *jsonSink) WithCorrelationID(id string) Interface {
+	fields := append(j.fields[:len(j.fields):len(j.fields)], jsonField{"correlation_id", id, true})
+	return newJSONSink(j.out, fields, j)
+}
+
+// WithFields returns a sink that adds a field for each key/value pair in kv
+// to each message, in addition to any fields of j.
+func (j *jsonSink) WithFields(kv ...interface{}) Interface {
+	fields := append(j.fields[:len(j.fields):len(j.fields)], fieldPairs(kv)...)
+	return newJSONSink(j.out, fields, j)
+}
+
+// owner returns the value masks for j are registered on: the jsonContext
+// wrapping j, if any, so that masks held by its context apply too.
+func (j *jsonSink) owner() interface{} {
+	if j.self != nil {
+		return j.self
+	}
+	return j
+}
+
+// replace applies the masks registered on the sinks j derives from. Messages
+// are masked by j itself before they reach write.
+func (j *jsonSink) replace(s string) string {
+	for p := j.parent; p != nil; p = p.parent {
+		s = mask(p.owner()).replace(s)
+	}
+	return s
+}
+
+// maskField applies the masks of j and the sinks it derives from to a field
+// value, as they apply to message arguments. Errors are replaced by their
+// text, which is all encoding/json would otherwise lose.
+func (j *jsonSink) maskField(v interface{}) interface{} {
+	if _, ok := v.(error); ok {
+		v = fmt.Sprint(v)
+	}
+	a := []interface{}{v}
+	for p := j; p != nil; p = p.parent {
+		a = mask(p.owner()).Args(a)
+	}
+	return a[0]
+}
+
+func (j *jsonSink) write(m Diagnostic) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	m.Msg = j.replace(m.Msg)
+	writeJSONDiagnostic(&buf, &j.out.opts, m)
+	for _, f := range j.fields {
+		name := f.name
+		if f.standard {
+			name = j.out.opts.fieldName(name)
+		} else if j.out.opts.isStandardField(name) {
+			name = "fields." + name
+		}
+		writeJSONField(&buf, name, j.maskField(f.value))
+	}
+	buf.WriteString("}\n")
+
+	j.out.mu.Lock()
+	defer j.out.mu.Unlock()
+	_, err := j.out.w.Write(buf.Bytes())
+	j.out.opts.error(err)
+}
+
+// writeJSONDiagnostic appends the standard fields of m to buf, named as
+// configured by WithFieldNames.
+func writeJSONDiagnostic(buf *bytes.Buffer, o *options, m Diagnostic) {
+	writeJSONField(buf, o.fieldName("severity"), m.Level)
+	if m.File != "" {
+		writeJSONField(buf, o.fieldName("file"), m.File)
+	}
+	if m.Line != 0 {
+		writeJSONField(buf, o.fieldName("line"), m.Line)
+	}
+	if m.Col != 0 {
+		writeJSONField(buf, o.fieldName("col"), m.Col)
+	}
+	writeJSONField(buf, o.fieldName("msg"), m.Msg)
+}
+
+// writeJSONField appends "name":value to buf, preceded by a comma unless it
+// is the first field. Values that cannot be encoded are formatted with
+// fmt.Sprint instead.
+func writeJSONField(buf *bytes.Buffer, name string, value interface{}) {
+	if buf.Len() > 1 {
+		buf.WriteByte(',')
+	}
+	k, _ := json.Marshal(name)
+	buf.Write(k)
+	buf.WriteByte(':')
+	v, err := json.Marshal(value)
+	if err != nil {
+		v, _ = json.Marshal(fmt.Sprint(value))
+	}
+	buf.Write(v)
+}
@@ -0,0 +1,140 @@
+package diag_test
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/mutility/diag"
+)
+
+type ctxKey string
+
+func TestJSONContext(t *testing.T) {
+	sb := &strings.Builder{}
+	keys := []interface{}{ctxKey("request_id"), ctxKey("user"), ctxKey("missing")}
+	newContext := diag.NewJSONContext(sb, keys, diag.WithFieldNames(map[string]string{"msg": "message"}))
+
+	ctx := context.WithValue(context.Background(), ctxKey("request_id"), "abc")
+	ctx = context.WithValue(ctx, ctxKey("user"), 42)
+	d := newContext(ctx)
+	diag.ErrorAt(d, "a.go", 1, 2, "failed")
+	diag.Print(newContext(context.Background()), "no values")
+
+	want := `{"severity":"error","file":"a.go","line":1,"col":2,"message":"failed","request_id":"abc","user":42}` + "\n" +
+		`{"severity":"print","message":"no values"}` + "\n"
+	if got := sb.String(); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestJSONCorrelationID(t *testing.T) {
+	sb := &strings.Builder{}
+	j := diag.NewJSON(sb)
+	diag.MaskValue(j, "secret")
+	t.Cleanup(func() { diag.Forget(j) })
+	d := diag.WithCorrelationID(j, "req-secret")
+	diag.Warningf(d, "using %s", "secret")
+	diag.Print(j, "uncorrelated")
+
+	want := `{"severity":"warning","msg":"using ***","correlation_id":"req-***"}` + "\n" +
+		`{"severity":"print","msg":"uncorrelated"}` + "\n"
+	if got := sb.String(); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestJSONFieldNames(t *testing.T) {
+	sb := &strings.Builder{}
+	d := diag.NewJSON(sb, diag.WithFieldNames(map[string]string{
+		"level":   "lvl",
+		"msg":     "message",
+		"unknown": "ignored",
+	}))
+	diag.WarningAt(d, "a.go", 1, 2, "careful")
+	diag.Print(diag.WithCorrelationID(d, "req"), "correlated")
+
+	want := `{"lvl":"warning","file":"a.go","line":1,"col":2,"message":"careful"}` + "\n" +
+		`{"lvl":"print","message":"correlated","correlation_id":"req"}` + "\n"
+	if got := sb.String(); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestJSON(t *testing.T) {
+	type line struct {
+		Severity string
+		File     *string
+		Line     *int
+		Col      *int
+		Msg      string
+	}
+	for _, tt := range []struct {
+		name     string
+		emit     func(diag.Interface)
+		severity string
+		located  bool
+		mask     string
+		msg      string
+	}{
+		{"debug", func(d diag.Interface) { diag.Debug(d, "m", 1) }, "debug", false, "", "m 1"},
+		{"debugatf", func(d diag.Interface) { diag.DebugAtf(d, "x.go", 10, 3, "m %d", 1) }, "debug", true, "", "m 1"},
+		{"info", func(d diag.Interface) { diag.Infof(d, "m %d", 1) }, "info", false, "", "m 1"},
+		{"infoat", func(d diag.Interface) { diag.InfoAt(d, "x.go", 10, 3, "m", 1) }, "info", true, "", "m 1"},
+		{"print", func(d diag.Interface) { diag.Print(d, "m", 1) }, "print", false, "", "m 1"},
+		{"printat", func(d diag.Interface) { diag.PrintAt(d, "x.go", 10, 3, "m", 1) }, "print", true, "", "m 1"},
+		{"warningf", func(d diag.Interface) { diag.Warningf(d, "m %d", 1) }, "warning", false, "", "m 1"},
+		{"warningat", func(d diag.Interface) { diag.WarningAt(d, "x.go", 10, 3, "m", 1) }, "warning", true, "", "m 1"},
+		{"error", func(d diag.Interface) { diag.Error(d, "m", 1) }, "error", false, "", "m 1"},
+		{"erroratf", func(d diag.Interface) { diag.ErrorAtf(d, "x.go", 10, 3, "m %d", 1) }, "error", true, "", "m 1"},
+		{"masked", func(d diag.Interface) { diag.ErrorAt(d, "x.go", 10, 3, "m", "secret") }, "error", true, "secret", "m ***"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			sb := &strings.Builder{}
+			d := diag.NewJSON(sb)
+			if tt.mask != "" {
+				diag.MaskValue(d, tt.mask)
+				t.Cleanup(func() { diag.Forget(d) })
+			}
+			tt.emit(d)
+
+			var got line
+			if err := json.Unmarshal([]byte(sb.String()), &got); err != nil {
+				t.Fatalf("unmarshal %q: %v", sb.String(), err)
+			}
+			if got.Severity != tt.severity || got.Msg != tt.msg {
+				t.Errorf("got severity %q msg %q; want %q %q", got.Severity, got.Msg, tt.severity, tt.msg)
+			}
+			if tt.located {
+				if got.File == nil || *got.File != "x.go" || got.Line == nil || *got.Line != 10 || got.Col == nil || *got.Col != 3 {
+					t.Errorf("got location %v %v %v; want x.go 10 3", got.File, got.Line, got.Col)
+				}
+			} else if got.File != nil || got.Line != nil || got.Col != nil {
+				t.Errorf("got location keys in %q", sb.String())
+			}
+		})
+	}
+}
+
+type secretError struct{ s string }
+
+func (e secretError) Error() string { return "failed with " + e.s }
+
+func TestJSONFieldMasks(t *testing.T) {
+	sb := &strings.Builder{}
+	newContext := diag.NewJSONContext(sb, []interface{}{ctxKey("token")})
+	ctx := context.WithValue(context.Background(), ctxKey("token"), "hunter2")
+	ctx = diag.MaskInContext(ctx, "hunter2")
+	d := newContext(ctx)
+	diag.MaskValue(d, "secret")
+	t.Cleanup(func() { diag.ClearMasks(d) })
+	f := diag.WithFields(d, "err", secretError{"secret"}, "who", secretStringer{"hunter2"}, "msg", "shadow", "n", 1)
+	diag.Print(f, "hello")
+
+	want := `{"severity":"print","msg":"hello","token":"***",` +
+		`"err":"failed with ***","who":"token=***","fields.msg":"shadow","n":1}` + "\n"
+	if got := sb.String(); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
@@ -0,0 +1,41 @@
+package diag
+
+import "strconv"
+
+// Level identifies the severity of a diagnostic.
+type Level int
+
+const (
+	// LevelNone is less severe than all other levels. It is reported by
+	// functions like MaxLevel.Max when there is no diagnostic to describe.
+	LevelNone Level = iota - 1
+
+	LevelDebug
+	LevelInfo
+	LevelPrint
+	LevelWarning
+	LevelError
+)
+
+func (l Level) String() string {
+	switch l {
+	case LevelNone:
+		return "none"
+	case LevelDebug:
+		return "debug"
+	case LevelInfo:
+		return "info"
+	case LevelPrint:
+		return "print"
+	case LevelWarning:
+		return "warning"
+	case LevelError:
+		return "error"
+	}
+	return "Level(" + strconv.Itoa(int(l)) + ")"
+}
+
+// MarshalText renders l as its String.
+func (l Level) MarshalText() ([]byte, error) {
+	return []byte(l.String()), nil
+}
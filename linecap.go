@@ -0,0 +1,18 @@
+package diag
+
+import "sync/atomic"
+
+// NewLineCap creates an Interface that forwards the first max messages to
+// inner, of any level, then prints "(output truncated)" once and discards
+// the rest.
+func NewLineCap(inner Interface, max int) Interface {
+	var n int64
+	return &funnel{emit: func(m Diagnostic) {
+		switch c := atomic.AddInt64(&n, 1); {
+		case c <= int64(max):
+			forward(inner, m)
+		case c == int64(max)+1:
+			Print(inner, "(output truncated)")
+		}
+	}}
+}
@@ -0,0 +1,47 @@
+package diag_test
+
+import (
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/mutility/diag"
+)
+
+func TestLineCap(t *testing.T) {
+	sb := &strings.Builder{}
+	d := diag.NewLineCap(diag.NewWriterDebug(sb), 3)
+	diag.Debug(d, "one")
+	diag.WarningAt(d, "a.go", 1, 2, "two")
+	diag.Errorf(d, "%s", "three")
+	diag.Error(d, "four")
+	diag.Print(d, "five")
+
+	want := "one\n[a.go:1.2] two\nthree\n(output truncated)\n"
+	if got := sb.String(); got != want {
+		t.Errorf("got %q; want %q", got, want)
+	}
+}
+
+func TestLineCapConcurrent(t *testing.T) {
+	lb := &lockedBuilder{}
+	d := diag.NewLineCap(diag.NewWriter(lb), 10)
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				diag.Warning(d, "msg")
+			}
+		}()
+	}
+	wg.Wait()
+
+	if n := lb.lines(); n != 11 {
+		t.Errorf("got %d lines; want 11", n)
+	}
+	if n := strings.Count(lb.String(), "(output truncated)"); n != 1 {
+		t.Errorf("got %d truncation notices; want 1", n)
+	}
+}
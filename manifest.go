@@ -0,0 +1,250 @@
+package diag
+
+import (
+	"encoding/json"
+	"io"
+	"sort"
+	"sync"
+)
+
+// Manifest collects each distinct diagnostic emitted, such as for generating
+// a catalog of a program's diagnostics. See NewManifest.
+type Manifest struct {
+	mu   sync.Mutex
+	seen map[ManifestEntry]struct{}
+}
+
+// ManifestEntry is a distinct diagnostic recorded by a Manifest. Template is
+// the format string of a formatted message. For a message logged without a
+// format, it is the first argument if that is a string, followed by "%v" for
+// each remaining argument, spaced as by Print; so Error(d, "open", path)
+// records "open %v" whatever the path. Templates are masked as messages are
+// by the Interface passed to NewManifest.
+type ManifestEntry struct {
+	Level    Level  `json:"severity"`
+	Template string `json:"template"`
+}
+
+// NewManifest creates an Interface that forwards to inner, recording each
+// distinct combination of level and message template in the returned
+// Manifest. Locations are not recorded, so the same message at two
+// locations is recorded once.
+func NewManifest(inner Interface) (*Manifest, Interface) {
+	m := &Manifest{seen: make(map[ManifestEntry]struct{})}
+	return m, &manifestWriter{m: m, d: inner}
+}
+
+// Entries returns the distinct entries recorded so far, sorted by level and
+// then by template.
+func (m *Manifest) Entries() []ManifestEntry {
+	m.mu.Lock()
+	entries := make([]ManifestEntry, 0, len(m.seen))
+	for e := range m.seen {
+		entries = append(entries, e)
+	}
+	m.mu.Unlock()
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Level != entries[j].Level {
+			return entries[i].Level < entries[j].Level
+		}
+		return entries[i].Template < entries[j].Template
+	})
+	return entries
+}
+
+// WriteJSON writes the entries returned by Entries to w as a JSON array.
+func (m *Manifest) WriteJSON(w io.Writer) error {
+	return json.NewEncoder(w).Encode(m.Entries())
+}
+
+func (m *Manifest) record(level Level, template string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.seen[ManifestEntry{level, template}] = struct{}{}
+}
+
+// manifestWriter records the template of each message and then reissues the
+// call on d. Unlike funnel, it sees format strings before they are applied.
+type manifestWriter struct {
+	m *Manifest
+	d Interface
+}
+
+// record records template, masked by d, at level.
+func (w *manifestWriter) record(level Level, template string) {
+	w.m.record(level, mask(w.d).replace(template))
+}
+
+// argsTemplate returns the template of a message logged without a format,
+// as described by ManifestEntry.
+func argsTemplate(a []interface{}) string {
+	t := make([]interface{}, len(a))
+	for i, v := range a {
+		if s, ok := v.(string); ok && i == 0 {
+			t[i] = s
+		} else {
+			t[i] = "%v"
+		}
+	}
+	return sprintln(t)
+}
+
+func (w *manifestWriter) Debug(a ...interface{}) {
+	if h := thelper(w.d); h != nil {
+		h()
+	}
+	w.record(LevelDebug, argsTemplate(a))
+	Debug(w.d, a...)
+}
+
+func (w *manifestWriter) Debugf(format string, a ...interface{}) {
+	if h := thelper(w.d); h != nil {
+		h()
+	}
+	w.record(LevelDebug, format)
+	Debugf(w.d, format, a...)
+}
+
+func (w *manifestWriter) DebugAt(file string, line, col int, a ...interface{}) {
+	if h := thelper(w.d); h != nil {
+		h()
+	}
+	w.record(LevelDebug, argsTemplate(a))
+	DebugAt(w.d, file, line, col, a...)
+}
+
+func (w *manifestWriter) DebugAtf(file string, line, col int, format string, a ...interface{}) {
+	if h := thelper(w.d); h != nil {
+		h()
+	}
+	w.record(LevelDebug, format)
+	DebugAtf(w.d, file, line, col, format, a...)
+}
+
+func (w *manifestWriter) Info(a ...interface{}) {
+	if h := thelper(w.d); h != nil {
+		h()
+	}
+	w.record(LevelInfo, argsTemplate(a))
+	Info(w.d, a...)
+}
+
+func (w *manifestWriter) Infof(format string, a ...interface{}) {
+	if h := thelper(w.d); h != nil {
+		h()
+	}
+	w.record(LevelInfo, format)
+	Infof(w.d, format, a...)
+}
+
+func (w *manifestWriter) InfoAt(file string, line, col int, a ...interface{}) {
+	if h := thelper(w.d); h != nil {
+		h()
+	}
+	w.record(LevelInfo, argsTemplate(a))
+	InfoAt(w.d, file, line, col, a...)
+}
+
+func (w *manifestWriter) InfoAtf(file string, line, col int, format string, a ...interface{}) {
+	if h := thelper(w.d); h != nil {
+		h()
+	}
+	w.record(LevelInfo, format)
+	InfoAtf(w.d, file, line, col, format, a...)
+}
+
+func (w *manifestWriter) Print(a ...interface{}) {
+	if h := thelper(w.d); h != nil {
+		h()
+	}
+	w.record(LevelPrint, argsTemplate(a))
+	Print(w.d, a...)
+}
+
+func (w *manifestWriter) Printf(format string, a ...interface{}) {
+	if h := thelper(w.d); h != nil {
+		h()
+	}
+	w.record(LevelPrint, format)
+	Printf(w.d, format, a...)
+}
+
+func (w *manifestWriter) PrintAt(file string, line, col int, a ...interface{}) {
+	if h := thelper(w.d); h != nil {
+		h()
+	}
+	w.record(LevelPrint, argsTemplate(a))
+	PrintAt(w.d, file, line, col, a...)
+}
+
+func (w *manifestWriter) PrintAtf(file string, line, col int, format string, a ...interface{}) {
+	if h := thelper(w.d); h != nil {
+		h()
+	}
+	w.record(LevelPrint, format)
+	PrintAtf(w.d, file, line, col, format, a...)
+}
+
+func (w *manifestWriter) Warning(a ...interface{}) {
+	if h := thelper(w.d); h != nil {
+		h()
+	}
+	w.record(LevelWarning, argsTemplate(a))
+	Warning(w.d, a...)
+}
+
+func (w *manifestWriter) Warningf(format string, a ...interface{}) {
+	if h := thelper(w.d); h != nil {
+		h()
+	}
+	w.record(LevelWarning, format)
+	Warningf(w.d, format, a...)
+}
+
+func (w *manifestWriter) WarningAt(file string, line, col int, a ...interface{}) {
+	if h := thelper(w.d); h != nil {
+		h()
+	}
+	w.record(LevelWarning, argsTemplate(a))
+	WarningAt(w.d, file, line, col, a...)
+}
+
+func (w *manifestWriter) WarningAtf(file string, line, col int, format string, a ...interface{}) {
+	if h := thelper(w.d); h != nil {
+		h()
+	}
+	w.record(LevelWarning, format)
+	WarningAtf(w.d, file, line, col, format, a...)
+}
+
+func (w *manifestWriter) Error(a ...interface{}) {
+	if h := thelper(w.d); h != nil {
+		h()
+	}
+	w.record(LevelError, argsTemplate(a))
+	Error(w.d, a...)
+}
+
+func (w *manifestWriter) Errorf(format string, a ...interface{}) {
+	if h := thelper(w.d); h != nil {
+		h()
+	}
+	w.record(LevelError, format)
+	Errorf(w.d, format, a...)
+}
+
+func (w *manifestWriter) ErrorAt(file string, line, col int, a ...interface{}) {
+	if h := thelper(w.d); h != nil {
+		h()
+	}
+	w.record(LevelError, argsTemplate(a))
+	ErrorAt(w.d, file, line, col, a...)
+}
+
+func (w *manifestWriter) ErrorAtf(file string, line, col int, format string, a ...interface{}) {
+	if h := thelper(w.d); h != nil {
+		h()
+	}
+	w.record(LevelError, format)
+	ErrorAtf(w.d, file, line, col, format, a...)
+}
@@ -0,0 +1,67 @@
+package diag_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mutility/diag"
+)
+
+func TestManifest(t *testing.T) {
+	sb := &strings.Builder{}
+	m, d := diag.NewManifest(diag.NewWriter(sb))
+	for i := 0; i < 3; i++ {
+		diag.Warningf(d, "retry %d of %d", i, 3)
+		diag.ErrorAtf(d, "a.go", i, 1, "bad value %q", "x")
+	}
+	diag.ErrorAt(d, "b.go", 1, 1, "bad value %q") // not formatted, but the same text
+	diag.Error(d, "fatal")
+	diag.Error(d, "fatal")
+	diag.Warning(d, "fatal")
+
+	var got strings.Builder
+	if err := m.WriteJSON(&got); err != nil {
+		t.Fatal(err)
+	}
+	want := `[{"severity":"warning","template":"fatal"},` +
+		`{"severity":"warning","template":"retry %d of %d"},` +
+		`{"severity":"error","template":"bad value %q"},` +
+		`{"severity":"error","template":"fatal"}]` + "\n"
+	if got.String() != want {
+		t.Errorf("got %s; want %s", got.String(), want)
+	}
+	if !strings.Contains(sb.String(), "retry 2 of 3\n") {
+		t.Errorf("not forwarded: %q", sb.String())
+	}
+}
+
+func TestManifestTemplates(t *testing.T) {
+	inner := diag.NewWriter(&strings.Builder{})
+	diag.MaskValue(inner, "hunter2")
+	t.Cleanup(func() { diag.ClearMasks(inner) })
+	m, d := diag.NewManifest(inner)
+	diag.Error(d, "open", "/tmp/a")
+	diag.Error(d, "open", "/tmp/b")
+	diag.Warning(d, 42, "retries")
+	diag.Print(d, "password hunter2")
+	diag.Printf(d, "login hunter2 %d", 1)
+
+	want := []diag.ManifestEntry{
+		{Level: diag.LevelPrint, Template: "login *** %d"},
+		{Level: diag.LevelPrint, Template: "password ***"},
+		{Level: diag.LevelWarning, Template: "%v %v"},
+		{Level: diag.LevelError, Template: "open %v"},
+	}
+	got := m.Entries()
+	if len(got) != len(want) {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("[%d] got %v; want %v", i, got[i], want[i])
+		}
+		if strings.Contains(got[i].Template, "hunter2") {
+			t.Errorf("[%d] unmasked: %q", i, got[i].Template)
+		}
+	}
+}
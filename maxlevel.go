@@ -0,0 +1,34 @@
+package diag
+
+import "sync/atomic"
+
+// MaxLevel tracks the most severe level emitted. See NewMaxLevel.
+type MaxLevel struct {
+	max int32 // Level+1, so the zero value is LevelNone
+}
+
+// NewMaxLevel creates an Interface that forwards to inner, recording the most
+// severe level emitted through it. This suits setting an overall result
+// status when counts are not needed.
+func NewMaxLevel(inner Interface) (*MaxLevel, Interface) {
+	ml := &MaxLevel{}
+	return ml, &funnel{emit: func(m Diagnostic) {
+		ml.record(m.Level)
+		forward(inner, m)
+	}}
+}
+
+// Max returns the most severe level emitted so far, or LevelNone if nothing
+// has been emitted.
+func (ml *MaxLevel) Max() Level {
+	return Level(atomic.LoadInt32(&ml.max) - 1)
+}
+
+func (ml *MaxLevel) record(l Level) {
+	for {
+		old := atomic.LoadInt32(&ml.max)
+		if int32(l)+1 <= old || atomic.CompareAndSwapInt32(&ml.max, old, int32(l)+1) {
+			return
+		}
+	}
+}
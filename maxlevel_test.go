@@ -0,0 +1,27 @@
+package diag_test
+
+import (
+	"io"
+	"testing"
+
+	"github.com/mutility/diag"
+)
+
+func TestMaxLevel(t *testing.T) {
+	ml, d := diag.NewMaxLevel(diag.NewWriter(io.Discard))
+	for _, tt := range []struct {
+		emit func()
+		want diag.Level
+	}{
+		{func() {}, diag.LevelNone},
+		{func() { diag.Print(d, "p") }, diag.LevelPrint},
+		{func() { diag.Debugf(d, "d") }, diag.LevelPrint},
+		{func() { diag.ErrorAt(d, "fn.go", 1, 2, "e") }, diag.LevelError},
+		{func() { diag.Warning(d, "w") }, diag.LevelError},
+	} {
+		tt.emit()
+		if got := ml.Max(); got != tt.want {
+			t.Errorf("got %v; want %v", got, tt.want)
+		}
+	}
+}
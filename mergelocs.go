@@ -0,0 +1,62 @@
+package diag
+
+import (
+	"strings"
+	"sync"
+)
+
+// MergeLocations holds located diagnostics until Flush merges those that
+// differ only by location. See NewMergeLocations.
+type MergeLocations struct {
+	inner Interface
+
+	mu     sync.Mutex
+	order  []Diagnostic // the first of each distinct level and message
+	others map[Diagnostic][]string
+}
+
+// NewMergeLocations creates an Interface that holds located diagnostics until
+// Flush is called, and forwards others to inner immediately. This suits
+// messages that apply to many locations, which would otherwise repeat.
+func NewMergeLocations(inner Interface) (*MergeLocations, Interface) {
+	ml := &MergeLocations{inner: inner, others: make(map[Diagnostic][]string)}
+	return ml, &funnel{emit: ml.record}
+}
+
+func (ml *MergeLocations) record(m Diagnostic) {
+	if m.File == "" && m.Line == 0 && m.Col == 0 {
+		forward(ml.inner, m)
+		return
+	}
+	ml.mu.Lock()
+	defer ml.mu.Unlock()
+	key := Diagnostic{Level: m.Level, Msg: m.Msg}
+	if others, ok := ml.others[key]; ok {
+		loc := strings.TrimSuffix(strings.TrimPrefix(FormatAtBracket(m.File, m.Line, m.Col), "["), "]")
+		ml.others[key] = append(others, loc)
+		return
+	}
+	ml.order = append(ml.order, m)
+	ml.others[key] = []string{}
+}
+
+// Flush emits the diagnostics held so far to inner, and forgets them. Each
+// distinct combination of level and message is emitted once, in the order
+// first seen, at its first location, and with any other locations appended
+// to the message, such as "(also at: b.go:3, c.go:7.2)".
+func (ml *MergeLocations) Flush() {
+	if h := thelper(ml.inner); h != nil {
+		h()
+	}
+	ml.mu.Lock()
+	order, others := ml.order, ml.others
+	ml.order, ml.others = nil, make(map[Diagnostic][]string)
+	ml.mu.Unlock()
+
+	for _, m := range order {
+		if locs := others[Diagnostic{Level: m.Level, Msg: m.Msg}]; len(locs) > 0 {
+			m.Msg += " (also at: " + strings.Join(locs, ", ") + ")"
+		}
+		forward(ml.inner, m)
+	}
+}
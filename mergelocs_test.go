@@ -0,0 +1,35 @@
+package diag_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mutility/diag"
+)
+
+func TestMergeLocations(t *testing.T) {
+	sb := &strings.Builder{}
+	ml, d := diag.NewMergeLocations(diag.NewWriter(sb))
+	diag.WarningAt(d, "a.go", 1, 2, "unused variable")
+	diag.ErrorAt(d, "a.go", 1, 2, "unused variable") // a different level
+	diag.WarningAt(d, "b.go", 3, 0, "unused variable")
+	diag.Warning(d, "not held")
+	diag.WarningAtf(d, "c.go", 7, 2, "unused %s", "variable")
+
+	if got, want := sb.String(), "not held\n"; got != want {
+		t.Errorf("before Flush: got %q; want %q", got, want)
+	}
+	sb.Reset()
+	ml.Flush()
+	want := "[a.go:1.2] unused variable (also at: b.go:3, c.go:7.2)\n" +
+		"[a.go:1.2] unused variable\n"
+	if got := sb.String(); got != want {
+		t.Errorf("got %q; want %q", got, want)
+	}
+
+	sb.Reset()
+	ml.Flush()
+	if got := sb.String(); got != "" {
+		t.Errorf("second flush: got %q; want nothing", got)
+	}
+}
@@ -0,0 +1,59 @@
+package diag
+
+// WithMinLevel creates an Interface that forwards to d only those messages
+// at least as severe as min, silently dropping the rest. This suits runtime
+// verbosity control, such as passing LevelDebug when -v is set and
+// LevelWarning otherwise.
+//
+// Groups and masks are passed through to d, so a Grouper renders its own
+// groups, and a ValueMasker owns the values masked on the result. If min is
+// more severe than LevelPrint, group titles are dropped like other prints,
+// and the messages of a group are forwarded as if outside it.
+func WithMinLevel(d Interface, min Level) Interface {
+	ml := &minLevel{inner: d, min: min}
+	ml.funnel.emit = ml.emit
+	return ml
+}
+
+type minLevel struct {
+	funnel
+	inner Interface
+	min   Level
+}
+
+func (ml *minLevel) emit(m Diagnostic) {
+	if m.Level >= ml.min {
+		forward(ml.inner, m)
+	}
+}
+
+// Group begins a group on the underlying Interface, and runs fn against an
+// Interface that filters its messages like ml. If prints are filtered, it
+// runs fn against ml instead, so no title is printed.
+func (ml *minLevel) Group(title string, fn func(Interface)) {
+	if h := thelper(ml.inner); h != nil {
+		h()
+	}
+	if ml.min > LevelPrint {
+		fn(ml)
+		return
+	}
+	Group(ml.inner, title, func(g Interface) {
+		fn(WithMinLevel(g, ml.min))
+	})
+}
+
+// MaskValue masks v on the underlying Interface.
+func (ml *minLevel) MaskValue(v string) {
+	MaskValue(ml.inner, v)
+}
+
+// UnmaskValue unmasks v on the underlying Interface.
+func (ml *minLevel) UnmaskValue(v string) {
+	UnmaskValue(ml.inner, v)
+}
+
+// ClearMasks clears the masks of the underlying Interface.
+func (ml *minLevel) ClearMasks() {
+	ClearMasks(ml.inner)
+}
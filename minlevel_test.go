@@ -0,0 +1,88 @@
+package diag_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mutility/diag"
+)
+
+func TestWithMinLevel(t *testing.T) {
+	emit := func(d diag.Interface) {
+		diag.Debug(d, "d")
+		diag.Debugf(d, "%s", "df")
+		diag.DebugAt(d, "fn.go", 1, 0, "da")
+		diag.Info(d, "i")
+		diag.Print(d, "p")
+		diag.PrintAtf(d, "fn.go", 2, 0, "%s", "paf")
+		diag.Warning(d, "w")
+		diag.WarningAtf(d, "fn.go", 3, 4, "%s", "waf")
+		diag.Error(d, "e secret")
+		diag.ErrorAt(d, "fn.go", 5, 0, "ea")
+	}
+	for _, tt := range []struct {
+		min  diag.Level
+		want string
+	}{
+		{diag.LevelDebug, "d\ndf\n[fn.go:1] da\ni\np\n[fn.go:2] paf\nw\n[fn.go:3.4] waf\ne ***\n[fn.go:5] ea\n"},
+		{diag.LevelPrint, "p\n[fn.go:2] paf\nw\n[fn.go:3.4] waf\ne ***\n[fn.go:5] ea\n"},
+		{diag.LevelWarning, "w\n[fn.go:3.4] waf\ne ***\n[fn.go:5] ea\n"},
+		{diag.LevelError, "e ***\n[fn.go:5] ea\n"},
+	} {
+		t.Run(tt.min.String(), func(t *testing.T) {
+			sb := &strings.Builder{}
+			d := diag.WithMinLevel(diag.NewWriterDebug(sb), tt.min)
+			diag.MaskValue(d, "secret")
+			t.Cleanup(func() { diag.Forget(d) })
+			emit(d)
+			if got := sb.String(); got != tt.want {
+				t.Errorf("got %q; want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithMinLevelGroup(t *testing.T) {
+	for _, tt := range []struct {
+		name  string
+		min   diag.Level
+		group func(diag.Interface) diag.Interface
+		want  string
+	}{
+		{"native", diag.LevelPrint, func(d diag.Interface) diag.Interface { return nativeGroup{d} }, "::group::g\nprinted\nkept\n::endgroup::\n"},
+		{"native-filtered", diag.LevelWarning, func(d diag.Interface) diag.Interface { return nativeGroup{d} }, "kept\n"},
+		{"fallback", diag.LevelPrint, func(d diag.Interface) diag.Interface { return d }, "g:\n  printed\n  kept\n"},
+		{"fallback-filtered", diag.LevelWarning, func(d diag.Interface) diag.Interface { return d }, "kept\n"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			sb := &strings.Builder{}
+			d := diag.WithMinLevel(tt.group(diag.NewWriterDebug(sb)), tt.min)
+			diag.Group(d, "g", func(d diag.Interface) {
+				diag.Print(d, "printed")
+				diag.Info(d, "dropped")
+				diag.Warning(d, "kept")
+			})
+			if got := sb.String(); got != tt.want {
+				t.Errorf("got %q; want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithMinLevelMasks(t *testing.T) {
+	sb := &strings.Builder{}
+	inner := diag.NewWriter(sb)
+	d := diag.WithMinLevel(inner, diag.LevelPrint)
+	diag.MaskValue(d, "one")
+	diag.MaskValue(d, "two")
+	diag.UnmaskValue(d, "one")
+	diag.Print(d, "one two")
+	diag.ClearMasks(d)
+	diag.Print(d, "one two")
+	if got, want := sb.String(), "one ***\none two\n"; got != want {
+		t.Errorf("got %q; want %q", got, want)
+	}
+	if diag.HasMasker(inner) {
+		t.Error("masks remain on inner")
+	}
+}
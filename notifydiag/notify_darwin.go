@@ -0,0 +1,13 @@
+package notifydiag
+
+import "strings"
+
+func platformNotify(title, body string) error {
+	script := "display notification " + quote(body) + " with title " + quote(title)
+	return run(nil, "osascript", "-e", script)
+}
+
+// quote renders s as an AppleScript string literal.
+func quote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
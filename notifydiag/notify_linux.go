@@ -0,0 +1,7 @@
+package notifydiag
+
+func platformNotify(title, body string) error {
+	// "--" ends the options, so a title or body starting with "-" is not
+	// parsed as one.
+	return run(nil, "notify-send", "--app-name", title, "--", title, body)
+}
@@ -0,0 +1,8 @@
+//go:build !darwin && !linux && !windows
+// +build !darwin,!linux,!windows
+
+package notifydiag
+
+func platformNotify(title, body string) error {
+	return nil
+}
@@ -0,0 +1,20 @@
+package notifydiag
+
+import "os"
+
+// toastScript shows a toast using the WinRT notification APIs. The title and
+// body are passed through the environment to avoid quoting issues.
+const toastScript = `
+[Windows.UI.Notifications.ToastNotificationManager, Windows.UI.Notifications, ContentType = WindowsRuntime] > $null
+$template = [Windows.UI.Notifications.ToastNotificationManager]::GetTemplateContent([Windows.UI.Notifications.ToastTemplateType]::ToastText02)
+$text = $template.GetElementsByTagName('text')
+$text.Item(0).AppendChild($template.CreateTextNode($env:NOTIFYDIAG_TITLE)) > $null
+$text.Item(1).AppendChild($template.CreateTextNode($env:NOTIFYDIAG_BODY)) > $null
+$toast = [Windows.UI.Notifications.ToastNotification]::new($template)
+[Windows.UI.Notifications.ToastNotificationManager]::CreateToastNotifier($env:NOTIFYDIAG_TITLE).Show($toast)
+`
+
+func platformNotify(title, body string) error {
+	env := append(os.Environ(), "NOTIFYDIAG_TITLE="+title, "NOTIFYDIAG_BODY="+body)
+	return run(env, "powershell", "-NoProfile", "-NonInteractive", "-Command", toastScript)
+}
@@ -0,0 +1,211 @@
+// package notifydiag adapts a diag.Interface to also raise desktop
+// notifications for errors.
+package notifydiag
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/mutility/diag"
+)
+
+// notify raises a desktop notification. It is implemented per platform, and
+// replaced in tests.
+var notify = platformNotify
+
+// notifyTimeout limits how long a notification may block the error that
+// raised it, such as when the notification service is not responding.
+const notifyTimeout = 2 * time.Second
+
+// run runs the named command with env, or the current environment if env is
+// nil, killing it if it does not finish within notifyTimeout.
+func run(env []string, name string, args ...string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), notifyTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Env = env
+	return cmd.Run()
+}
+
+// Interface returns a diag.Interface that forwards everything to inner, and
+// additionally raises a desktop notification titled appName for each error.
+// Notifications use osascript on macOS, notify-send on Linux, and a toast on
+// Windows; other platforms do not notify. Failures to notify are ignored,
+// and a notification that takes longer than a couple of seconds is abandoned
+// so that it does not stall the program.
+func Interface(inner diag.Interface, appName string) diag.Interface {
+	return &notifier{inner, appName}
+}
+
+type notifier struct {
+	inner diag.Interface
+	app   string
+}
+
+func (n *notifier) Debug(a ...interface{}) {
+	if h := thelper(n.inner); h != nil {
+		h()
+	}
+	diag.Debug(n.inner, a...)
+}
+
+func (n *notifier) Debugf(format string, a ...interface{}) {
+	if h := thelper(n.inner); h != nil {
+		h()
+	}
+	diag.Debugf(n.inner, format, a...)
+}
+
+func (n *notifier) DebugAt(file string, line, col int, a ...interface{}) {
+	if h := thelper(n.inner); h != nil {
+		h()
+	}
+	diag.DebugAt(n.inner, file, line, col, a...)
+}
+
+func (n *notifier) DebugAtf(file string, line, col int, format string, a ...interface{}) {
+	if h := thelper(n.inner); h != nil {
+		h()
+	}
+	diag.DebugAtf(n.inner, file, line, col, format, a...)
+}
+
+func (n *notifier) Info(a ...interface{}) {
+	if h := thelper(n.inner); h != nil {
+		h()
+	}
+	diag.Info(n.inner, a...)
+}
+
+func (n *notifier) Infof(format string, a ...interface{}) {
+	if h := thelper(n.inner); h != nil {
+		h()
+	}
+	diag.Infof(n.inner, format, a...)
+}
+
+func (n *notifier) InfoAt(file string, line, col int, a ...interface{}) {
+	if h := thelper(n.inner); h != nil {
+		h()
+	}
+	diag.InfoAt(n.inner, file, line, col, a...)
+}
+
+func (n *notifier) InfoAtf(file string, line, col int, format string, a ...interface{}) {
+	if h := thelper(n.inner); h != nil {
+		h()
+	}
+	diag.InfoAtf(n.inner, file, line, col, format, a...)
+}
+
+func (n *notifier) Print(a ...interface{}) {
+	if h := thelper(n.inner); h != nil {
+		h()
+	}
+	diag.Print(n.inner, a...)
+}
+
+func (n *notifier) Printf(format string, a ...interface{}) {
+	if h := thelper(n.inner); h != nil {
+		h()
+	}
+	diag.Printf(n.inner, format, a...)
+}
+
+func (n *notifier) PrintAt(file string, line, col int, a ...interface{}) {
+	if h := thelper(n.inner); h != nil {
+		h()
+	}
+	diag.PrintAt(n.inner, file, line, col, a...)
+}
+
+func (n *notifier) PrintAtf(file string, line, col int, format string, a ...interface{}) {
+	if h := thelper(n.inner); h != nil {
+		h()
+	}
+	diag.PrintAtf(n.inner, file, line, col, format, a...)
+}
+
+func (n *notifier) Warning(a ...interface{}) {
+	if h := thelper(n.inner); h != nil {
+		h()
+	}
+	diag.Warning(n.inner, a...)
+}
+
+func (n *notifier) Warningf(format string, a ...interface{}) {
+	if h := thelper(n.inner); h != nil {
+		h()
+	}
+	diag.Warningf(n.inner, format, a...)
+}
+
+func (n *notifier) WarningAt(file string, line, col int, a ...interface{}) {
+	if h := thelper(n.inner); h != nil {
+		h()
+	}
+	diag.WarningAt(n.inner, file, line, col, a...)
+}
+
+func (n *notifier) WarningAtf(file string, line, col int, format string, a ...interface{}) {
+	if h := thelper(n.inner); h != nil {
+		h()
+	}
+	diag.WarningAtf(n.inner, file, line, col, format, a...)
+}
+
+func (n *notifier) Error(a ...interface{}) {
+	if h := thelper(n.inner); h != nil {
+		h()
+	}
+	diag.Error(n.inner, a...)
+	n.notify("", 0, 0, sprintln(a))
+}
+
+func (n *notifier) Errorf(format string, a ...interface{}) {
+	if h := thelper(n.inner); h != nil {
+		h()
+	}
+	diag.Errorf(n.inner, format, a...)
+	n.notify("", 0, 0, fmt.Sprintf(format, a...))
+}
+
+func (n *notifier) ErrorAt(file string, line, col int, a ...interface{}) {
+	if h := thelper(n.inner); h != nil {
+		h()
+	}
+	diag.ErrorAt(n.inner, file, line, col, a...)
+	n.notify(file, line, col, sprintln(a))
+}
+
+func (n *notifier) ErrorAtf(file string, line, col int, format string, a ...interface{}) {
+	if h := thelper(n.inner); h != nil {
+		h()
+	}
+	diag.ErrorAtf(n.inner, file, line, col, format, a...)
+	n.notify(file, line, col, fmt.Sprintf(format, a...))
+}
+
+func (n *notifier) notify(file string, line, col int, msg string) {
+	if loc := diag.FormatAt(file, line, col); loc != "" {
+		msg = loc + " " + msg
+	}
+	_ = notify(n.app, msg)
+}
+
+func sprintln(a []interface{}) string {
+	return strings.TrimSuffix(fmt.Sprintln(a...), "\n")
+}
+
+// thelper retrieves a t.Helper() method if i implements it.
+func thelper(i interface{}) func() {
+	if h, ok := i.(interface {
+		Helper()
+	}); ok {
+		return h.Helper
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package notifydiag
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mutility/diag"
+)
+
+func TestNotify(t *testing.T) {
+	type note struct{ title, body string }
+	var got []note
+	defer func(orig func(string, string) error) { notify = orig }(notify)
+	notify = func(title, body string) error {
+		got = append(got, note{title, body})
+		return nil
+	}
+
+	sb := &strings.Builder{}
+	d := Interface(diag.NewWriterDebug(sb), "app")
+	diag.MaskValue(d, "secret")
+	diag.Debug(d, "debug")
+	diag.Printf(d, "print")
+	diag.WarningAt(d, "fn.go", 1, 2, "warning")
+	diag.Error(d, "error", "secret")
+	diag.Errorf(d, "errorf %d", 1)
+	diag.ErrorAt(d, "fn.go", 3, 4, "errorat")
+	diag.ErrorAtf(d, "fn.go", 5, 0, "errorat%s", "f")
+
+	want := []note{
+		{"app", "error ***"},
+		{"app", "errorf 1"},
+		{"app", "[fn.go:3.4] errorat"},
+		{"app", "[fn.go:5] erroratf"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d notifications %q; want %d", len(got), got, len(want))
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("notification %d: got %q; want %q", i, got[i], want[i])
+		}
+	}
+
+	wantOut := "debug\nprint\n[fn.go:1.2] warning\nerror ***\nerrorf 1\n[fn.go:3.4] errorat\n[fn.go:5] erroratf\n"
+	if got := sb.String(); got != wantOut {
+		t.Errorf("forwarded %q; want %q", got, wantOut)
+	}
+}
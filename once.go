@@ -0,0 +1,68 @@
+package diag
+
+import "sync"
+
+// seenOnce holds the keys used with WarnOnce, ErrorOnce, and DebugOnce.
+var seenOnce sync.Map
+
+// first reports whether key is being seen for the first time.
+func first(key string) bool {
+	_, seen := seenOnce.LoadOrStore(key, struct{}{})
+	return !seen
+}
+
+// WarnOnce outputs a warning message the first time key is used with any of
+// the ...Once functions in this process, and does nothing afterwards. This
+// suits deprecation notices. Nothing is output if w is nil, but key is still
+// marked as used.
+func WarnOnce(w Warninger, key string, a ...interface{}) {
+	if h := thelper(w); h != nil {
+		h()
+	}
+	if first(key) {
+		Warning(w, a...)
+	}
+}
+
+// ErrorOnce outputs an error message the first time key is used with any of
+// the ...Once functions in this process, and does nothing afterwards.
+func ErrorOnce(e Errorer, key string, a ...interface{}) {
+	if h := thelper(e); h != nil {
+		h()
+	}
+	if first(key) {
+		Error(e, a...)
+	}
+}
+
+// DebugOnce outputs a debug message the first time key is used with any of
+// the ...Once functions in this process, and does nothing afterwards.
+func DebugOnce(d Debugger, key string, a ...interface{}) {
+	if h := thelper(d); h != nil {
+		h()
+	}
+	if first(key) {
+		Debug(d, a...)
+	}
+}
+
+// ResetOnce forgets that key was used, so the next ...Once call with it
+// outputs again. This is intended for tests.
+func ResetOnce(key string) {
+	seenOnce.Delete(key)
+}
+
+// Once creates an Interface that forwards to inner only the first message
+// with each text, regardless of its level or location, for as long as the
+// Interface is used. This suits deprecation notices issued from code that
+// runs repeatedly. Unlike WarnOnce and friends, the messages seen are held
+// by the Interface rather than for the whole process. It is safe for
+// concurrent use.
+func Once(inner Interface) Interface {
+	var seen sync.Map
+	return &funnel{emit: func(m Diagnostic) {
+		if _, dup := seen.LoadOrStore(m.Msg, struct{}{}); !dup {
+			forward(inner, m)
+		}
+	}}
+}
@@ -0,0 +1,49 @@
+package diag_test
+
+import (
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/mutility/diag"
+)
+
+func TestWarnOnce(t *testing.T) {
+	defer diag.ResetOnce("test-warn-once")
+	sb := &strings.Builder{}
+	d := diag.NewWriterDebug(sb)
+	for i := 0; i < 3; i++ {
+		diag.WarnOnce(d, "test-warn-once", "deprecated", i)
+		diag.ErrorOnce(d, "test-warn-once", "shared key", i)
+	}
+	diag.DebugOnce(d, "test-debug-once", "debug")
+	diag.DebugOnce(d, "test-debug-once", "debug")
+	diag.ResetOnce("test-debug-once")
+	diag.DebugOnce(d, "test-debug-once", "again")
+	diag.ResetOnce("test-debug-once")
+
+	want := "deprecated 0\ndebug\nagain\n"
+	if got := sb.String(); got != want {
+		t.Errorf("got %q; want %q", got, want)
+	}
+}
+
+func TestOnce(t *testing.T) {
+	sb := &strings.Builder{}
+	d := diag.Once(diag.NewWriter(sb))
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			diag.Warningf(d, "flag --%s is deprecated", "foo")
+		}()
+	}
+	wg.Wait()
+	diag.ErrorAt(d, "a.go", 1, 0, "flag --foo is deprecated") // level and location are ignored
+	diag.Print(d, "other")
+
+	if got, want := sb.String(), "flag --foo is deprecated\nother\n"; got != want {
+		t.Errorf("got %q; want %q", got, want)
+	}
+}
@@ -0,0 +1,145 @@
+package diag
+
+import (
+	"io"
+	"os"
+	"time"
+)
+
+// Option configures optional behavior of the constructors that accept it.
+type Option func(*options)
+
+type options struct {
+	onError  func(error)
+	terminal *bool
+	indent   *string
+	clock    Clock
+	hostname *string
+	fields   map[string]string
+	colors   *Colors
+
+	unlocatedFirst bool
+	timeColumn     bool
+}
+
+func newOptions(opts []Option) options {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// WithErrorHandler arranges for fn to receive errors, such as failed writes,
+// that would otherwise be discarded.
+func WithErrorHandler(fn func(error)) Option {
+	return func(o *options) { o.onError = fn }
+}
+
+// WithClock sets the Clock used by time-based features, such as the Time
+// passed to the template of NewTemplated.
+func WithClock(c Clock) Option {
+	return func(o *options) { o.clock = c }
+}
+
+// WithTerminal overrides detection of whether output is going to a terminal,
+// for constructors whose behavior depends on it.
+func WithTerminal(on bool) Option {
+	return func(o *options) { o.terminal = &on }
+}
+
+// WithHostname sets the hostname reported by constructors that include one,
+// instead of the value returned by os.Hostname.
+func WithHostname(name string) Option {
+	return func(o *options) { o.hostname = &name }
+}
+
+// WithIndent sets the string used for each level of indentation.
+func WithIndent(indent string) Option {
+	return func(o *options) { o.indent = &indent }
+}
+
+// WithColors sets the colors used by NewColor for each level.
+func WithColors(c Colors) Option {
+	return func(o *options) { o.colors = &c }
+}
+
+// WithUnlocatedFirst arranges for a Buffered to flush messages without a
+// location before all located messages, instead of in their original
+// positions. Only NewBuffered honors it; other constructors ignore it.
+func WithUnlocatedFirst() Option {
+	return func(o *options) { o.unlocatedFirst = true }
+}
+
+// WithTimeColumn arranges for NewCSV to write each message's time in a
+// leading column. Times come from the Clock passed to WithClock, if any, and
+// otherwise from the system clock. Only NewCSV honors it; other constructors
+// ignore it.
+func WithTimeColumn() Option {
+	return func(o *options) { o.timeColumn = true }
+}
+
+// WithFieldNames renames the fields written by structured targets such as
+// NewJSON, to match the schema of another system. Keys name the standard
+// fields "severity" (also accepted as "level"), "file", "line", "col", "msg",
+// and "correlation_id"; values are the names to write instead. Unknown keys
+// are ignored, and fields without a key keep their default name.
+func WithFieldNames(names map[string]string) Option {
+	return func(o *options) {
+		o.fields = make(map[string]string, len(names))
+		for k, v := range names {
+			if k == "level" {
+				k = "severity"
+			}
+			o.fields[k] = v
+		}
+	}
+}
+
+// fieldName returns the name to write for the standard field name.
+func (o *options) fieldName(name string) string {
+	if n, ok := o.fields[name]; ok {
+		return n
+	}
+	return name
+}
+
+// isStandardField reports whether name is written for one of the standard
+// fields.
+func (o *options) isStandardField(name string) bool {
+	for _, f := range []string{"severity", "file", "line", "col", "msg", "correlation_id"} {
+		if o.fieldName(f) == name {
+			return true
+		}
+	}
+	return false
+}
+
+// now returns the current time from the Clock passed to WithClock, or from
+// the system clock.
+func (o *options) now() time.Time {
+	if o.clock != nil {
+		return o.clock.Now()
+	}
+	return time.Now()
+}
+
+func (o *options) error(err error) {
+	if err != nil && o.onError != nil {
+		o.onError(err)
+	}
+}
+
+// isTerminal reports whether w should be treated as a terminal, honoring
+// WithTerminal if supplied.
+func (o *options) isTerminal(w io.Writer) bool {
+	if o.terminal != nil {
+		return *o.terminal
+	}
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	fi, err := f.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,48 @@
+package diag
+
+import "sync"
+
+// PerFile tallies errors and warnings by the file they were reported at. See
+// NewPerFile.
+type PerFile struct {
+	mu     sync.Mutex
+	counts map[string]struct{ Errors, Warnings int }
+}
+
+// NewPerFile creates an Interface that forwards to inner, counting errors and
+// warnings by file. Diagnostics without a location are counted under the
+// empty file name.
+func NewPerFile(inner Interface) (*PerFile, Interface) {
+	pf := &PerFile{counts: make(map[string]struct{ Errors, Warnings int })}
+	return pf, &funnel{emit: func(m Diagnostic) {
+		pf.record(m)
+		forward(inner, m)
+	}}
+}
+
+func (pf *PerFile) record(m Diagnostic) {
+	if m.Level != LevelError && m.Level != LevelWarning {
+		return
+	}
+	pf.mu.Lock()
+	defer pf.mu.Unlock()
+	c := pf.counts[m.File]
+	if m.Level == LevelError {
+		c.Errors++
+	} else {
+		c.Warnings++
+	}
+	pf.counts[m.File] = c
+}
+
+// Counts returns a copy of the tallies so far. Files with no errors or
+// warnings are absent.
+func (pf *PerFile) Counts() map[string]struct{ Errors, Warnings int } {
+	pf.mu.Lock()
+	defer pf.mu.Unlock()
+	counts := make(map[string]struct{ Errors, Warnings int }, len(pf.counts))
+	for file, c := range pf.counts {
+		counts[file] = c
+	}
+	return counts
+}
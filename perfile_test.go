@@ -0,0 +1,29 @@
+package diag_test
+
+import (
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/mutility/diag"
+)
+
+func TestPerFile(t *testing.T) {
+	pf, d := diag.NewPerFile(diag.NewWriter(io.Discard))
+	diag.ErrorAt(d, "a.go", 1, 1, "e")
+	diag.ErrorAtf(d, "a.go", 2, 1, "e%d", 2)
+	diag.WarningAt(d, "a.go", 3, 1, "w")
+	diag.WarningAtf(d, "b.go", 1, 1, "w%d", 2)
+	diag.Warning(d, "unlocated")
+	diag.Print(d, "not counted")
+	diag.Debug(d, "not counted")
+
+	want := map[string]struct{ Errors, Warnings int }{
+		"a.go": {2, 1},
+		"b.go": {0, 1},
+		"":     {0, 1},
+	}
+	if got := pf.Counts(); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("got %v; want %v", got, want)
+	}
+}
@@ -0,0 +1,40 @@
+package diag
+
+// WithPrefix creates an Interface that prepends prefix, such as "[auth] ", to
+// every message before forwarding it to d. Unlike NewPrefixed, which prefixes
+// each line of a byte stream, WithPrefix operates on messages, so it applies
+// equally to every severity and to sinks that are not writers.
+//
+// Locations are forwarded unchanged, so d renders any location bracket before
+// the prefix, as in "[file.go:1.2] [auth] message".
+//
+// Values masked on the result are masked in the arguments only, never in the
+// prefix. Values masked on d apply to the whole message, prefix included.
+// Groups are passed through to d.
+func WithPrefix(d Interface, prefix string) Interface {
+	p := &prefixed{inner: d, prefix: prefix}
+	p.funnel.emit = p.emit
+	return p
+}
+
+type prefixed struct {
+	funnel
+	inner  Interface
+	prefix string
+}
+
+func (p *prefixed) emit(m Diagnostic) {
+	m.Msg = p.prefix + m.Msg
+	forward(p.inner, m)
+}
+
+// Group begins a group on the underlying Interface, and runs fn against an
+// Interface that prefixes its messages like p.
+func (p *prefixed) Group(title string, fn func(Interface)) {
+	if h := thelper(p.inner); h != nil {
+		h()
+	}
+	Group(p.inner, title, func(g Interface) {
+		fn(WithPrefix(g, p.prefix))
+	})
+}
@@ -0,0 +1,23 @@
+package diag_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mutility/diag"
+)
+
+func TestWithPrefix(t *testing.T) {
+	sb := &strings.Builder{}
+	d := diag.WithPrefix(diag.NewWriter(sb), "[auth] ")
+	diag.MaskValue(d, "auth")
+	t.Cleanup(func() { diag.Forget(d) })
+	diag.Warningf(d, "token for %s rejected", "auth")
+	diag.ErrorAt(d, "login.go", 12, 3, "bad password")
+
+	want := "[auth] token for *** rejected\n" +
+		"[login.go:12.3] [auth] bad password\n"
+	if got := sb.String(); got != want {
+		t.Errorf("got %q; want %q", got, want)
+	}
+}
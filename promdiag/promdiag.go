@@ -0,0 +1,196 @@
+// package promdiag adapts a diag.Interface to also count diagnostics in a
+// Prometheus counter vector. It is a separate module so that only its users
+// depend on the Prometheus client.
+package promdiag
+
+import (
+	"github.com/mutility/diag"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Interface returns a diag.Interface that forwards everything to inner, and
+// increments the counter in vec with label "level" set to the level of each
+// message: "debug", "info", "print", "warning", or "error". The vector must have
+// exactly that one label.
+func Interface(inner diag.Interface, vec *prometheus.CounterVec) diag.Interface {
+	return &counter{inner, vec}
+}
+
+type counter struct {
+	inner diag.Interface
+	vec   *prometheus.CounterVec
+}
+
+func (c *counter) inc(l diag.Level) {
+	c.vec.WithLabelValues(l.String()).Inc()
+}
+
+func (c *counter) Debug(a ...interface{}) {
+	if h := thelper(c.inner); h != nil {
+		h()
+	}
+	c.inc(diag.LevelDebug)
+	diag.Debug(c.inner, a...)
+}
+
+func (c *counter) Debugf(format string, a ...interface{}) {
+	if h := thelper(c.inner); h != nil {
+		h()
+	}
+	c.inc(diag.LevelDebug)
+	diag.Debugf(c.inner, format, a...)
+}
+
+func (c *counter) DebugAt(file string, line, col int, a ...interface{}) {
+	if h := thelper(c.inner); h != nil {
+		h()
+	}
+	c.inc(diag.LevelDebug)
+	diag.DebugAt(c.inner, file, line, col, a...)
+}
+
+func (c *counter) DebugAtf(file string, line, col int, format string, a ...interface{}) {
+	if h := thelper(c.inner); h != nil {
+		h()
+	}
+	c.inc(diag.LevelDebug)
+	diag.DebugAtf(c.inner, file, line, col, format, a...)
+}
+
+func (c *counter) Info(a ...interface{}) {
+	if h := thelper(c.inner); h != nil {
+		h()
+	}
+	c.inc(diag.LevelInfo)
+	diag.Info(c.inner, a...)
+}
+
+func (c *counter) Infof(format string, a ...interface{}) {
+	if h := thelper(c.inner); h != nil {
+		h()
+	}
+	c.inc(diag.LevelInfo)
+	diag.Infof(c.inner, format, a...)
+}
+
+func (c *counter) InfoAt(file string, line, col int, a ...interface{}) {
+	if h := thelper(c.inner); h != nil {
+		h()
+	}
+	c.inc(diag.LevelInfo)
+	diag.InfoAt(c.inner, file, line, col, a...)
+}
+
+func (c *counter) InfoAtf(file string, line, col int, format string, a ...interface{}) {
+	if h := thelper(c.inner); h != nil {
+		h()
+	}
+	c.inc(diag.LevelInfo)
+	diag.InfoAtf(c.inner, file, line, col, format, a...)
+}
+
+func (c *counter) Print(a ...interface{}) {
+	if h := thelper(c.inner); h != nil {
+		h()
+	}
+	c.inc(diag.LevelPrint)
+	diag.Print(c.inner, a...)
+}
+
+func (c *counter) Printf(format string, a ...interface{}) {
+	if h := thelper(c.inner); h != nil {
+		h()
+	}
+	c.inc(diag.LevelPrint)
+	diag.Printf(c.inner, format, a...)
+}
+
+func (c *counter) PrintAt(file string, line, col int, a ...interface{}) {
+	if h := thelper(c.inner); h != nil {
+		h()
+	}
+	c.inc(diag.LevelPrint)
+	diag.PrintAt(c.inner, file, line, col, a...)
+}
+
+func (c *counter) PrintAtf(file string, line, col int, format string, a ...interface{}) {
+	if h := thelper(c.inner); h != nil {
+		h()
+	}
+	c.inc(diag.LevelPrint)
+	diag.PrintAtf(c.inner, file, line, col, format, a...)
+}
+
+func (c *counter) Warning(a ...interface{}) {
+	if h := thelper(c.inner); h != nil {
+		h()
+	}
+	c.inc(diag.LevelWarning)
+	diag.Warning(c.inner, a...)
+}
+
+func (c *counter) Warningf(format string, a ...interface{}) {
+	if h := thelper(c.inner); h != nil {
+		h()
+	}
+	c.inc(diag.LevelWarning)
+	diag.Warningf(c.inner, format, a...)
+}
+
+func (c *counter) WarningAt(file string, line, col int, a ...interface{}) {
+	if h := thelper(c.inner); h != nil {
+		h()
+	}
+	c.inc(diag.LevelWarning)
+	diag.WarningAt(c.inner, file, line, col, a...)
+}
+
+func (c *counter) WarningAtf(file string, line, col int, format string, a ...interface{}) {
+	if h := thelper(c.inner); h != nil {
+		h()
+	}
+	c.inc(diag.LevelWarning)
+	diag.WarningAtf(c.inner, file, line, col, format, a...)
+}
+
+func (c *counter) Error(a ...interface{}) {
+	if h := thelper(c.inner); h != nil {
+		h()
+	}
+	c.inc(diag.LevelError)
+	diag.Error(c.inner, a...)
+}
+
+func (c *counter) Errorf(format string, a ...interface{}) {
+	if h := thelper(c.inner); h != nil {
+		h()
+	}
+	c.inc(diag.LevelError)
+	diag.Errorf(c.inner, format, a...)
+}
+
+func (c *counter) ErrorAt(file string, line, col int, a ...interface{}) {
+	if h := thelper(c.inner); h != nil {
+		h()
+	}
+	c.inc(diag.LevelError)
+	diag.ErrorAt(c.inner, file, line, col, a...)
+}
+
+func (c *counter) ErrorAtf(file string, line, col int, format string, a ...interface{}) {
+	if h := thelper(c.inner); h != nil {
+		h()
+	}
+	c.inc(diag.LevelError)
+	diag.ErrorAtf(c.inner, file, line, col, format, a...)
+}
+
+// thelper retrieves a t.Helper() method if i implements it.
+func thelper(i interface{}) func() {
+	if h, ok := i.(interface {
+		Helper()
+	}); ok {
+		return h.Helper
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package promdiag_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mutility/diag"
+	"github.com/mutility/diag/promdiag"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestInterface(t *testing.T) {
+	vec := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "diagnostics_total",
+		Help: "Diagnostics emitted, by level.",
+	}, []string{"level"})
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(vec)
+
+	sb := &strings.Builder{}
+	d := promdiag.Interface(diag.NewWriterDebug(sb), vec)
+	diag.Debug(d, "debug")
+	diag.Printf(d, "print %d", 1)
+	diag.Print(d, "print")
+	diag.WarningAt(d, "fn.go", 1, 2, "warning")
+	diag.Error(d, "error")
+	diag.ErrorAtf(d, "fn.go", 3, 4, "error %d", 2)
+	diag.Errorf(d, "error %d", 3)
+
+	for level, want := range map[string]float64{
+		"debug":   1,
+		"print":   2,
+		"warning": 1,
+		"error":   3,
+	} {
+		if got := testutil.ToFloat64(vec.WithLabelValues(level)); got != want {
+			t.Errorf("%s: got %v; want %v", level, got, want)
+		}
+	}
+
+	want := "debug\nprint 1\nprint\n[fn.go:1.2] warning\nerror\n[fn.go:3.4] error 2\nerror 3\n"
+	if got := sb.String(); got != want {
+		t.Errorf("forwarded %q; want %q", got, want)
+	}
+}
@@ -0,0 +1,105 @@
+package diag
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// ErrorAtRange outputs an error message about the span from startLine and
+// startCol to endLine and endCol, unless e is nil or holds a nil pointer. If
+// e does not implement RangeErrorAter or RangeErrorAtfer, it falls back to
+// ErrorAt with the start of the span.
+func ErrorAtRange(e Errorer, file string, startLine, startCol, endLine, endCol int, a ...interface{}) {
+	if isNil(e) {
+		return
+	}
+	if h := thelper(e); h != nil {
+		h()
+	}
+	if er, ok := e.(RangeErrorAter); ok {
+		er.ErrorAtRange(file, startLine, startCol, endLine, endCol, mask(e).Args(a)...)
+	} else if erf, ok := e.(RangeErrorAtfer); ok {
+		erf.ErrorAtRangef(file, startLine, startCol, endLine, endCol, "%s", fmt.Sprint(mask(e).Args(a)...))
+	} else {
+		ErrorAt(e, file, startLine, startCol, a...)
+	}
+}
+
+// ErrorAtRangef outputs a formatted error message about a span like
+// ErrorAtRange, unless e is nil or holds a nil pointer.
+func ErrorAtRangef(e Errorer, file string, startLine, startCol, endLine, endCol int, format string, a ...interface{}) {
+	if isNil(e) {
+		return
+	}
+	if h := thelper(e); h != nil {
+		h()
+	}
+	if erf, ok := e.(RangeErrorAtfer); ok {
+		m := mask(e)
+		erf.ErrorAtRangef(file, startLine, startCol, endLine, endCol, m.Format(format), m.Args(a)...)
+	} else if er, ok := e.(RangeErrorAter); ok {
+		m := mask(e)
+		er.ErrorAtRange(file, startLine, startCol, endLine, endCol, fmt.Sprintf(m.Format(format), m.Args(a)...))
+	} else {
+		ErrorAtf(e, file, startLine, startCol, format, a...)
+	}
+}
+
+// WarningAtRange outputs a warning message about the span from startLine and
+// startCol to endLine and endCol, unless w is nil or holds a nil pointer. If
+// w does not implement RangeWarningAter or RangeWarningAtfer, it falls back
+// to WarningAt with the start of the span.
+func WarningAtRange(w Warninger, file string, startLine, startCol, endLine, endCol int, a ...interface{}) {
+	if isNil(w) {
+		return
+	}
+	if h := thelper(w); h != nil {
+		h()
+	}
+	if wr, ok := w.(RangeWarningAter); ok {
+		wr.WarningAtRange(file, startLine, startCol, endLine, endCol, mask(w).Args(a)...)
+	} else if wrf, ok := w.(RangeWarningAtfer); ok {
+		wrf.WarningAtRangef(file, startLine, startCol, endLine, endCol, "%s", fmt.Sprint(mask(w).Args(a)...))
+	} else {
+		WarningAt(w, file, startLine, startCol, a...)
+	}
+}
+
+// WarningAtRangef outputs a formatted warning message about a span like
+// WarningAtRange, unless w is nil or holds a nil pointer.
+func WarningAtRangef(w Warninger, file string, startLine, startCol, endLine, endCol int, format string, a ...interface{}) {
+	if isNil(w) {
+		return
+	}
+	if h := thelper(w); h != nil {
+		h()
+	}
+	if wrf, ok := w.(RangeWarningAtfer); ok {
+		m := mask(w)
+		wrf.WarningAtRangef(file, startLine, startCol, endLine, endCol, m.Format(format), m.Args(a)...)
+	} else if wr, ok := w.(RangeWarningAter); ok {
+		m := mask(w)
+		wr.WarningAtRange(file, startLine, startCol, endLine, endCol, fmt.Sprintf(m.Format(format), m.Args(a)...))
+	} else {
+		WarningAtf(w, file, startLine, startCol, format, a...)
+	}
+}
+
+// FormatAtRange returns a substring of
+// `[{{ file }}:{{ startLine }}.{{ startCol }}-{{ endLine }}.{{ endCol }}]`,
+// such as [file.go:10.3-10.9] or [file.go:10.3-12.5]. Like FormatAtBracket,
+// it terminates the inner string at the first zero value, and returns nothing
+// if file is empty.
+func FormatAtRange(file string, startLine, startCol, endLine, endCol int) string {
+	if file == "" {
+		return ""
+	}
+	loc := "[" + file
+	for i, n := range []int{startLine, startCol, endLine, endCol} {
+		if n == 0 {
+			break
+		}
+		loc += [...]string{":", ".", "-", "."}[i] + strconv.Itoa(n)
+	}
+	return loc + "]"
+}
@@ -0,0 +1,76 @@
+package diag_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/mutility/diag"
+)
+
+func TestFormatAtRange(t *testing.T) {
+	for _, tt := range []struct {
+		file                   string
+		sline, scol, eline, ec int
+		want                   string
+	}{
+		{"", 10, 3, 10, 9, ""},
+		{"fn.go", 0, 3, 10, 9, "[fn.go]"},
+		{"fn.go", 10, 0, 10, 9, "[fn.go:10]"},
+		{"fn.go", 10, 3, 0, 9, "[fn.go:10.3]"},
+		{"fn.go", 10, 3, 12, 0, "[fn.go:10.3-12]"},
+		{"fn.go", 10, 3, 10, 9, "[fn.go:10.3-10.9]"},
+		{"fn.go", 10, 3, 12, 5, "[fn.go:10.3-12.5]"},
+	} {
+		if got := diag.FormatAtRange(tt.file, tt.sline, tt.scol, tt.eline, tt.ec); got != tt.want {
+			t.Errorf("FormatAtRange(%q, %d, %d, %d, %d): got %q; want %q", tt.file, tt.sline, tt.scol, tt.eline, tt.ec, got, tt.want)
+		}
+	}
+}
+
+// ranged implements RangeErrorAter and RangeWarningAtfer, formatting the span
+// with FormatAtRange.
+type ranged struct {
+	fill
+}
+
+func (r *ranged) ErrorAtRange(file string, sl, sc, el, ec int, a ...interface{}) {
+	r.Error(append([]interface{}{diag.FormatAtRange(file, sl, sc, el, ec)}, a...)...)
+}
+
+func (r *ranged) WarningAtRangef(file string, sl, sc, el, ec int, format string, a ...interface{}) {
+	r.Warning(diag.FormatAtRange(file, sl, sc, el, ec), fmt.Sprintf(format, a...))
+}
+
+func TestAtRange(t *testing.T) {
+	d := &fill{}
+	r := &ranged{}
+	diag.MaskValue(r, "secret")
+	for _, tt := range []struct {
+		name  string
+		emit  func(diag.Interface)
+		get   func(*fill) string
+		want  string
+		rwant string
+	}{
+		{"ErrorAtRange", func(d diag.Interface) { diag.ErrorAtRange(d, "fn.go", 10, 3, 10, 9, "e", "secret") }, (*fill).error,
+			"[fn.go:10.3] e secret\n", "[fn.go:10.3-10.9] e ***\n"},
+		{"ErrorAtRangef", func(d diag.Interface) { diag.ErrorAtRangef(d, "fn.go", 10, 3, 12, 5, "e %d", 1) }, (*fill).error,
+			"[fn.go:10.3] e 1\n", "[fn.go:10.3-12.5] e 1\n"},
+		{"WarningAtRange", func(d diag.Interface) { diag.WarningAtRange(d, "fn.go", 10, 0, 12, 5, "w", 2) }, (*fill).warning,
+			"[fn.go:10] w 2\n", "[fn.go:10] w2\n"},
+		{"WarningAtRangef", func(d diag.Interface) { diag.WarningAtRangef(d, "fn.go", 10, 3, 12, 5, "w %s", "secret") }, (*fill).warning,
+			"[fn.go:10.3] w secret\n", "[fn.go:10.3-12.5] w ***\n"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.emit(d)
+			if got := tt.get(d); got != tt.want {
+				t.Errorf("fallback: got %q; want %q", got, tt.want)
+			}
+			tt.emit(r)
+			if got := tt.get(&r.fill); got != tt.rwant {
+				t.Errorf("ranged: got %q; want %q", got, tt.rwant)
+			}
+		})
+	}
+	diag.Forget(r)
+}
@@ -0,0 +1,26 @@
+package diag
+
+import "regexp"
+
+// RewriteRule replaces matches of Re with Repl, which may refer to
+// submatches as described by regexp.Regexp.Expand.
+type RewriteRule = struct {
+	Re   *regexp.Regexp
+	Repl string
+}
+
+// NewRewrite creates an Interface that applies each rule in turn to each
+// rendered message before forwarding it to inner. This suits normalizing
+// messages, such as collapsing whitespace or shortening paths. Locations are
+// forwarded unchanged.
+//
+// Messages are masked before the rules are applied, so rules see mask
+// replacements rather than the masked values.
+func NewRewrite(inner Interface, rules []RewriteRule) Interface {
+	return &funnel{emit: func(m Diagnostic) {
+		for _, r := range rules {
+			m.Msg = r.Re.ReplaceAllString(m.Msg, r.Repl)
+		}
+		forward(inner, m)
+	}}
+}
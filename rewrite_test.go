@@ -0,0 +1,26 @@
+package diag_test
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/mutility/diag"
+)
+
+func TestRewrite(t *testing.T) {
+	sb := &strings.Builder{}
+	d := diag.NewRewrite(diag.NewWriter(sb), []diag.RewriteRule{
+		{Re: regexp.MustCompile(`/home/\w+/`), Repl: "~/"},
+		{Re: regexp.MustCompile(`\s+`), Repl: " "}, // sees the output of the first rule
+		{Re: regexp.MustCompile(`~/(\S+)`), Repl: "<$1>"},
+	})
+	diag.MaskValue(d, "hunter2")
+	t.Cleanup(func() { diag.Forget(d) })
+	diag.ErrorAt(d, "a.go", 1, 2, "cannot  read\t/home/alice/.netrc:", "hunter2")
+
+	want := "[a.go:1.2] cannot read <.netrc:> ***\n"
+	if got := sb.String(); got != want {
+		t.Errorf("got %q; want %q", got, want)
+	}
+}
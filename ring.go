@@ -0,0 +1,130 @@
+package diag
+
+import (
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// ring holds the most recent diagnostics up to its capacity.
+type ring struct {
+	mu   sync.Mutex
+	buf  []Diagnostic
+	next int
+	full bool
+}
+
+func newRing(size int) *ring {
+	if size < 1 {
+		size = 1
+	}
+	return &ring{buf: make([]Diagnostic, size)}
+}
+
+func (r *ring) add(m Diagnostic) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.buf[r.next] = m
+	r.next++
+	if r.next == len(r.buf) {
+		r.next = 0
+		r.full = true
+	}
+}
+
+// snapshot returns the held diagnostics, oldest first.
+func (r *ring) snapshot() []Diagnostic {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.full {
+		return append([]Diagnostic(nil), r.buf[:r.next]...)
+	}
+	return append(append([]Diagnostic(nil), r.buf[r.next:]...), r.buf[:r.next]...)
+}
+
+// Ring is an Interface that retains the most recent messages it forwards.
+// See NewRing.
+type Ring struct {
+	funnel
+
+	inner Interface
+	ring  *ring
+}
+
+// NewRing creates a Ring that forwards to d, and also retains the last n
+// messages with their levels and locations, dropping the oldest past n. This
+// suits crash reports that should include recent context without keeping
+// the whole log. It may be used concurrently.
+func NewRing(d Interface, n int) *Ring {
+	r := &Ring{inner: d, ring: newRing(n)}
+	r.funnel.emit = r.record
+	return r
+}
+
+func (r *Ring) record(m Diagnostic) {
+	forward(r.inner, m)
+	m.Msg = mask(r.inner).replace(m.Msg)
+	r.ring.add(m)
+}
+
+// Snapshot returns the retained messages, oldest first, masked as they were
+// by d.
+func (r *Ring) Snapshot() []Diagnostic {
+	return r.ring.snapshot()
+}
+
+// HTTPRing serves the most recent diagnostics over HTTP. See NewHTTPRing.
+type HTTPRing struct {
+	ring *ring
+}
+
+// NewHTTPRing creates an Interface that forwards to inner, and also keeps the
+// last size messages for HTTPRing to serve, masked as they were by inner,
+// such as at /debug/log:
+//
+//     ring, log := diag.NewHTTPRing(log, 200)
+//     http.Handle("/debug/log", ring)
+//
+func NewHTTPRing(inner Interface, size int) (*HTTPRing, Interface) {
+	h := &HTTPRing{newRing(size)}
+	return h, &funnel{emit: func(m Diagnostic) {
+		forward(inner, m)
+		m.Msg = mask(inner).replace(m.Msg)
+		h.ring.add(m)
+	}}
+}
+
+// ServeHTTP responds with the kept messages, newest first. If the request
+// accepts application/json, they are rendered as a JSON array of objects with
+// the keys severity, file, line, col, and msg; otherwise as lines of text.
+func (h *HTTPRing) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	msgs := h.ring.snapshot()
+	for i, j := 0, len(msgs)-1; i < j; i, j = i+1, j-1 {
+		msgs[i], msgs[j] = msgs[j], msgs[i]
+	}
+
+	if acceptsJSON(r.Header.Get("Accept")) {
+		w.Header().Set("Content-Type", "application/json")
+		if msgs == nil {
+			msgs = []Diagnostic{}
+		}
+		json.NewEncoder(w).Encode(msgs)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	for _, m := range msgs {
+		fmt.Fprintln(w, m)
+	}
+}
+
+func acceptsJSON(accept string) bool {
+	for _, part := range strings.Split(accept, ",") {
+		if mt, _, err := mime.ParseMediaType(part); err == nil && mt == "application/json" {
+			return true
+		}
+	}
+	return false
+}
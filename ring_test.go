@@ -0,0 +1,100 @@
+package diag_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mutility/diag"
+)
+
+func TestHTTPRing(t *testing.T) {
+	ring, d := diag.NewHTTPRing(diag.NewWriter(io.Discard), 3)
+	diag.Print(d, "one")
+	diag.Warning(d, "two")
+	diag.ErrorAt(d, "fn.go", 10, 3, "three")
+	diag.Debugf(d, "%s", "four")
+	diag.Printf(d, "five")
+
+	t.Run("text", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		ring.ServeHTTP(rec, httptest.NewRequest("GET", "/debug/log", nil))
+		want := "print: five\ndebug: four\nerror: [fn.go:10.3] three\n"
+		if got := rec.Body.String(); got != want {
+			t.Errorf("got %q; want %q", got, want)
+		}
+	})
+
+	t.Run("json", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/debug/log", nil)
+		req.Header.Set("Accept", "text/html, application/json;q=0.9")
+		ring.ServeHTTP(rec, req)
+		if got, want := rec.Header().Get("Content-Type"), "application/json"; got != want {
+			t.Errorf("content type: got %q; want %q", got, want)
+		}
+		var got []map[string]interface{}
+		if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+			t.Fatal(err)
+		}
+		want := []map[string]interface{}{
+			{"severity": "print", "msg": "five"},
+			{"severity": "debug", "msg": "four"},
+			{"severity": "error", "file": "fn.go", "line": 10.0, "col": 3.0, "msg": "three"},
+		}
+		if len(got) != len(want) {
+			t.Fatalf("got %d entries; want %d", len(got), len(want))
+		}
+		for i := range want {
+			if len(got[i]) != len(want[i]) {
+				t.Errorf("[%d] got %v; want %v", i, got[i], want[i])
+			}
+			for k, v := range want[i] {
+				if got[i][k] != v {
+					t.Errorf("[%d].%s got %v; want %v", i, k, got[i][k], v)
+				}
+			}
+		}
+	})
+}
+
+func TestHTTPRingMasksInner(t *testing.T) {
+	inner := diag.NewWriter(io.Discard)
+	diag.MaskValue(inner, "secret")
+	t.Cleanup(func() { diag.ClearMasks(inner) })
+	ring, d := diag.NewHTTPRing(inner, 3)
+	diag.Print(d, "token", "secret")
+
+	rec := httptest.NewRecorder()
+	ring.ServeHTTP(rec, httptest.NewRequest("GET", "/debug/log", nil))
+	if got, want := rec.Body.String(), "print: token ***\n"; got != want {
+		t.Errorf("got %q; want %q", got, want)
+	}
+}
+
+func TestRing(t *testing.T) {
+	sb := &strings.Builder{}
+	inner := diag.NewWriter(sb)
+	diag.MaskValue(inner, "secret")
+	t.Cleanup(func() { diag.Forget(inner) })
+	r := diag.NewRing(inner, 3)
+	for i := 1; i <= 4; i++ {
+		diag.Printf(r, "line %d", i)
+	}
+	diag.ErrorAt(r, "fn.go", 10, 3, "secret")
+
+	want := []diag.Diagnostic{
+		{Level: diag.LevelPrint, Msg: "line 3"},
+		{Level: diag.LevelPrint, Msg: "line 4"},
+		{Level: diag.LevelError, File: "fn.go", Line: 10, Col: 3, Msg: "***"},
+	}
+	if got := r.Snapshot(); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("got %v; want %v", got, want)
+	}
+	if got := strings.Count(sb.String(), "\n"); got != 5 {
+		t.Errorf("forwarded %d lines; want 5", got)
+	}
+}
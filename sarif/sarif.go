@@ -0,0 +1,168 @@
+// Package sarif provides a diag.Interface that reports errors, warnings,
+// and info messages as results in a SARIF 2.1.0 log, for consumption by
+// static-analysis tooling such as GitHub code scanning.
+package sarif
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Sink is a diag.Interface that accumulates results until Close writes them
+// as a SARIF log. Errors and warnings become results of level "error" and
+// "warning", and info messages become results of level "note". Debug and
+// print messages are not findings, and are discarded.
+type Sink struct {
+	// Tool names the tool in the log. New sets it to the program's name.
+	Tool string
+
+	w       io.Writer
+	mu      sync.Mutex
+	results []result
+}
+
+// New creates a Sink that writes its log to w when closed.
+func New(w io.Writer) *Sink {
+	return &Sink{
+		Tool:    strings.TrimSuffix(filepath.Base(os.Args[0]), ".exe"),
+		w:       w,
+		results: []result{},
+	}
+}
+
+type result struct {
+	Level     string     `json:"level"`
+	Message   message    `json:"message"`
+	Locations []location `json:"locations,omitempty"`
+}
+
+type message struct {
+	Text string `json:"text"`
+}
+
+type location struct {
+	PhysicalLocation physicalLocation `json:"physicalLocation"`
+}
+
+type physicalLocation struct {
+	ArtifactLocation artifactLocation `json:"artifactLocation"`
+	Region           *region          `json:"region,omitempty"`
+}
+
+type artifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type region struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn,omitempty"`
+}
+
+// record adds a result. Like diag.FormatAtBracket, the location stops at the
+// first zero value: a result without a file has no location, and one without
+// a line has no region.
+func (s *Sink) record(level, file string, line, col int, msg string) {
+	r := result{Level: level, Message: message{msg}}
+	if file != "" {
+		loc := location{physicalLocation{ArtifactLocation: artifactLocation{filepath.ToSlash(file)}}}
+		if line != 0 {
+			loc.PhysicalLocation.Region = &region{StartLine: line, StartColumn: col}
+		}
+		r.Locations = []location{loc}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.results = append(s.results, r)
+}
+
+// Close writes the SARIF log of all results recorded so far to w.
+func (s *Sink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	type driver struct {
+		Name string `json:"name"`
+	}
+	type tool struct {
+		Driver driver `json:"driver"`
+	}
+	type run struct {
+		Tool    tool     `json:"tool"`
+		Results []result `json:"results"`
+	}
+	b, err := json.MarshalIndent(struct {
+		Version string `json:"version"`
+		Schema  string `json:"$schema"`
+		Runs    []run  `json:"runs"`
+	}{
+		Version: "2.1.0",
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Runs:    []run{{tool{driver{s.Tool}}, s.results}},
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = s.w.Write(append(b, '\n'))
+	return err
+}
+
+func (s *Sink) Debug(a ...interface{}) {}
+
+func (s *Sink) Print(a ...interface{}) {}
+
+func (s *Sink) Info(a ...interface{}) {
+	s.record("note", "", 0, 0, sprintln(a))
+}
+
+func (s *Sink) Infof(format string, a ...interface{}) {
+	s.record("note", "", 0, 0, fmt.Sprintf(format, a...))
+}
+
+func (s *Sink) InfoAt(file string, line, col int, a ...interface{}) {
+	s.record("note", file, line, col, sprintln(a))
+}
+
+func (s *Sink) InfoAtf(file string, line, col int, format string, a ...interface{}) {
+	s.record("note", file, line, col, fmt.Sprintf(format, a...))
+}
+
+func (s *Sink) Warning(a ...interface{}) {
+	s.record("warning", "", 0, 0, sprintln(a))
+}
+
+func (s *Sink) Warningf(format string, a ...interface{}) {
+	s.record("warning", "", 0, 0, fmt.Sprintf(format, a...))
+}
+
+func (s *Sink) WarningAt(file string, line, col int, a ...interface{}) {
+	s.record("warning", file, line, col, sprintln(a))
+}
+
+func (s *Sink) WarningAtf(file string, line, col int, format string, a ...interface{}) {
+	s.record("warning", file, line, col, fmt.Sprintf(format, a...))
+}
+
+func (s *Sink) Error(a ...interface{}) {
+	s.record("error", "", 0, 0, sprintln(a))
+}
+
+func (s *Sink) Errorf(format string, a ...interface{}) {
+	s.record("error", "", 0, 0, fmt.Sprintf(format, a...))
+}
+
+func (s *Sink) ErrorAt(file string, line, col int, a ...interface{}) {
+	s.record("error", file, line, col, sprintln(a))
+}
+
+func (s *Sink) ErrorAtf(file string, line, col int, format string, a ...interface{}) {
+	s.record("error", file, line, col, fmt.Sprintf(format, a...))
+}
+
+// sprintln formats a like fmt.Sprintln, without the trailing newline.
+func sprintln(a []interface{}) string {
+	return strings.TrimSuffix(fmt.Sprintln(a...), "\n")
+}
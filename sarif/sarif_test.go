@@ -0,0 +1,35 @@
+package sarif
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/mutility/diag"
+)
+
+func TestSink(t *testing.T) {
+	buf := &bytes.Buffer{}
+	s := New(buf)
+	s.Tool = "lint"
+	diag.MaskValue(s, "hunter2")
+	diag.Debug(s, "debug")
+	diag.Print(s, "print")
+	diag.InfoAt(s, "doc.go", 1, 0, "consider a doc comment")
+	diag.Warningf(s, "%d files skipped", 2)
+	diag.WarningAt(s, "dir/sub.go", 3, 7, "shadowed")
+	diag.ErrorAtf(s, "main.go", 0, 0, "password %s in source", "hunter2")
+	diag.Error(s, "failed:", "badly")
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	want, err := os.ReadFile("testdata/golden.sarif")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := buf.String(); got != string(want) {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+	diag.Forget(s)
+}
@@ -0,0 +1,15 @@
+module github.com/mutility/diag/sentrydiag
+
+go 1.21
+
+require (
+	github.com/getsentry/sentry-go v0.27.0
+	github.com/mutility/diag v0.0.0
+)
+
+require (
+	golang.org/x/sys v0.6.0 // indirect
+	golang.org/x/text v0.8.0 // indirect
+)
+
+replace github.com/mutility/diag => ../
@@ -0,0 +1,222 @@
+// package sentrydiag adapts a diag.Interface to also report diagnostics to
+// Sentry. It is a separate module so that only its users depend on the Sentry
+// SDK.
+package sentrydiag
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/mutility/diag"
+)
+
+// Interface returns a diag.Interface that forwards everything to inner, and
+// also reports each message to hub. Errors and warnings are captured as
+// events of the matching Sentry level, with any location in the tags "file",
+// "line", and "col". Debug, Info, and Print messages are added as
+// breadcrumbs, so they accompany later events.
+func Interface(inner diag.Interface, hub *sentry.Hub) diag.Interface {
+	return &reporter{inner, hub}
+}
+
+type reporter struct {
+	inner diag.Interface
+	hub   *sentry.Hub
+}
+
+func (r *reporter) breadcrumb(level sentry.Level, msg string) {
+	r.hub.AddBreadcrumb(&sentry.Breadcrumb{Level: level, Message: msg}, nil)
+}
+
+func (r *reporter) capture(level sentry.Level, file string, line, col int, msg string) {
+	ev := sentry.NewEvent()
+	ev.Level = level
+	ev.Message = msg
+	if file != "" {
+		ev.Tags["file"] = file
+	}
+	if line != 0 {
+		ev.Tags["line"] = strconv.Itoa(line)
+	}
+	if col != 0 {
+		ev.Tags["col"] = strconv.Itoa(col)
+	}
+	r.hub.CaptureEvent(ev)
+}
+
+func (r *reporter) Debug(a ...interface{}) {
+	if h := thelper(r.inner); h != nil {
+		h()
+	}
+	r.breadcrumb(sentry.LevelDebug, sprintln(a))
+	diag.Debug(r.inner, a...)
+}
+
+func (r *reporter) Debugf(format string, a ...interface{}) {
+	if h := thelper(r.inner); h != nil {
+		h()
+	}
+	r.breadcrumb(sentry.LevelDebug, fmt.Sprintf(format, a...))
+	diag.Debugf(r.inner, format, a...)
+}
+
+func (r *reporter) DebugAt(file string, line, col int, a ...interface{}) {
+	if h := thelper(r.inner); h != nil {
+		h()
+	}
+	r.breadcrumb(sentry.LevelDebug, sprintln(a))
+	diag.DebugAt(r.inner, file, line, col, a...)
+}
+
+func (r *reporter) DebugAtf(file string, line, col int, format string, a ...interface{}) {
+	if h := thelper(r.inner); h != nil {
+		h()
+	}
+	r.breadcrumb(sentry.LevelDebug, fmt.Sprintf(format, a...))
+	diag.DebugAtf(r.inner, file, line, col, format, a...)
+}
+
+func (r *reporter) Info(a ...interface{}) {
+	if h := thelper(r.inner); h != nil {
+		h()
+	}
+	r.breadcrumb(sentry.LevelInfo, sprintln(a))
+	diag.Info(r.inner, a...)
+}
+
+func (r *reporter) Infof(format string, a ...interface{}) {
+	if h := thelper(r.inner); h != nil {
+		h()
+	}
+	r.breadcrumb(sentry.LevelInfo, fmt.Sprintf(format, a...))
+	diag.Infof(r.inner, format, a...)
+}
+
+func (r *reporter) InfoAt(file string, line, col int, a ...interface{}) {
+	if h := thelper(r.inner); h != nil {
+		h()
+	}
+	r.breadcrumb(sentry.LevelInfo, sprintln(a))
+	diag.InfoAt(r.inner, file, line, col, a...)
+}
+
+func (r *reporter) InfoAtf(file string, line, col int, format string, a ...interface{}) {
+	if h := thelper(r.inner); h != nil {
+		h()
+	}
+	r.breadcrumb(sentry.LevelInfo, fmt.Sprintf(format, a...))
+	diag.InfoAtf(r.inner, file, line, col, format, a...)
+}
+
+func (r *reporter) Print(a ...interface{}) {
+	if h := thelper(r.inner); h != nil {
+		h()
+	}
+	r.breadcrumb(sentry.LevelInfo, sprintln(a))
+	diag.Print(r.inner, a...)
+}
+
+func (r *reporter) Printf(format string, a ...interface{}) {
+	if h := thelper(r.inner); h != nil {
+		h()
+	}
+	r.breadcrumb(sentry.LevelInfo, fmt.Sprintf(format, a...))
+	diag.Printf(r.inner, format, a...)
+}
+
+func (r *reporter) PrintAt(file string, line, col int, a ...interface{}) {
+	if h := thelper(r.inner); h != nil {
+		h()
+	}
+	r.breadcrumb(sentry.LevelInfo, sprintln(a))
+	diag.PrintAt(r.inner, file, line, col, a...)
+}
+
+func (r *reporter) PrintAtf(file string, line, col int, format string, a ...interface{}) {
+	if h := thelper(r.inner); h != nil {
+		h()
+	}
+	r.breadcrumb(sentry.LevelInfo, fmt.Sprintf(format, a...))
+	diag.PrintAtf(r.inner, file, line, col, format, a...)
+}
+
+func (r *reporter) Warning(a ...interface{}) {
+	if h := thelper(r.inner); h != nil {
+		h()
+	}
+	r.capture(sentry.LevelWarning, "", 0, 0, sprintln(a))
+	diag.Warning(r.inner, a...)
+}
+
+func (r *reporter) Warningf(format string, a ...interface{}) {
+	if h := thelper(r.inner); h != nil {
+		h()
+	}
+	r.capture(sentry.LevelWarning, "", 0, 0, fmt.Sprintf(format, a...))
+	diag.Warningf(r.inner, format, a...)
+}
+
+func (r *reporter) WarningAt(file string, line, col int, a ...interface{}) {
+	if h := thelper(r.inner); h != nil {
+		h()
+	}
+	r.capture(sentry.LevelWarning, file, line, col, sprintln(a))
+	diag.WarningAt(r.inner, file, line, col, a...)
+}
+
+func (r *reporter) WarningAtf(file string, line, col int, format string, a ...interface{}) {
+	if h := thelper(r.inner); h != nil {
+		h()
+	}
+	r.capture(sentry.LevelWarning, file, line, col, fmt.Sprintf(format, a...))
+	diag.WarningAtf(r.inner, file, line, col, format, a...)
+}
+
+func (r *reporter) Error(a ...interface{}) {
+	if h := thelper(r.inner); h != nil {
+		h()
+	}
+	r.capture(sentry.LevelError, "", 0, 0, sprintln(a))
+	diag.Error(r.inner, a...)
+}
+
+func (r *reporter) Errorf(format string, a ...interface{}) {
+	if h := thelper(r.inner); h != nil {
+		h()
+	}
+	r.capture(sentry.LevelError, "", 0, 0, fmt.Sprintf(format, a...))
+	diag.Errorf(r.inner, format, a...)
+}
+
+func (r *reporter) ErrorAt(file string, line, col int, a ...interface{}) {
+	if h := thelper(r.inner); h != nil {
+		h()
+	}
+	r.capture(sentry.LevelError, file, line, col, sprintln(a))
+	diag.ErrorAt(r.inner, file, line, col, a...)
+}
+
+func (r *reporter) ErrorAtf(file string, line, col int, format string, a ...interface{}) {
+	if h := thelper(r.inner); h != nil {
+		h()
+	}
+	r.capture(sentry.LevelError, file, line, col, fmt.Sprintf(format, a...))
+	diag.ErrorAtf(r.inner, file, line, col, format, a...)
+}
+
+// sprintln formats a like fmt.Sprintln, without the trailing newline.
+func sprintln(a []interface{}) string {
+	return strings.TrimSuffix(fmt.Sprintln(a...), "\n")
+}
+
+// thelper retrieves a t.Helper() method if i implements it.
+func thelper(i interface{}) func() {
+	if h, ok := i.(interface {
+		Helper()
+	}); ok {
+		return h.Helper
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package sentrydiag_test
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/mutility/diag"
+	"github.com/mutility/diag/sentrydiag"
+)
+
+type fakeTransport struct {
+	mu     sync.Mutex
+	events []*sentry.Event
+}
+
+func (f *fakeTransport) Flush(time.Duration) bool       { return true }
+func (f *fakeTransport) Configure(sentry.ClientOptions) {}
+func (f *fakeTransport) SendEvent(ev *sentry.Event) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.events = append(f.events, ev)
+}
+
+func TestInterface(t *testing.T) {
+	ft := &fakeTransport{}
+	client, err := sentry.NewClient(sentry.ClientOptions{Dsn: "https://key@sentry.invalid/1", Transport: ft})
+	if err != nil {
+		t.Fatal(err)
+	}
+	hub := sentry.NewHub(client, sentry.NewScope())
+
+	sb := &strings.Builder{}
+	d := sentrydiag.Interface(diag.NewWriterDebug(sb), hub)
+	diag.MaskValue(d, "hunter2")
+	diag.Debug(d, "starting")
+	diag.Printf(d, "using %s", "hunter2")
+	diag.WarningAt(d, "a.go", 1, 2, "careful")
+	diag.ErrorAtf(d, "b.go", 3, 0, "bad %s", "value")
+	diag.Error(d, "plain")
+
+	if got, want := sb.String(), "starting\nusing ***\n[a.go:1.2] careful\n[b.go:3] bad value\nplain\n"; got != want {
+		t.Errorf("inner: got %q; want %q", got, want)
+	}
+
+	want := []struct {
+		level sentry.Level
+		msg   string
+		tags  map[string]string
+	}{
+		{sentry.LevelWarning, "careful", map[string]string{"file": "a.go", "line": "1", "col": "2"}},
+		{sentry.LevelError, "bad value", map[string]string{"file": "b.go", "line": "3"}},
+		{sentry.LevelError, "plain", map[string]string{}},
+	}
+	if len(ft.events) != len(want) {
+		t.Fatalf("got %d events; want %d", len(ft.events), len(want))
+	}
+	for i, w := range want {
+		ev := ft.events[i]
+		if ev.Level != w.level || ev.Message != w.msg {
+			t.Errorf("event %d: got %s %q; want %s %q", i, ev.Level, ev.Message, w.level, w.msg)
+		}
+		for k, v := range w.tags {
+			if ev.Tags[k] != v {
+				t.Errorf("event %d tag %s: got %q; want %q", i, k, ev.Tags[k], v)
+			}
+		}
+		for _, k := range []string{"file", "line", "col"} {
+			if _, ok := w.tags[k]; !ok && ev.Tags[k] != "" {
+				t.Errorf("event %d: unexpected tag %s=%q", i, k, ev.Tags[k])
+			}
+		}
+	}
+
+	crumbs := ft.events[0].Breadcrumbs
+	if len(crumbs) != 2 || crumbs[0].Level != sentry.LevelDebug || crumbs[0].Message != "starting" ||
+		crumbs[1].Level != sentry.LevelInfo || crumbs[1].Message != "using ***" {
+		t.Errorf("breadcrumbs: got %+v", crumbs)
+	}
+}
@@ -0,0 +1,48 @@
+package diag
+
+import "strings"
+
+// snippetTabWidth is the width of the tab stops used to align carets.
+const snippetTabWidth = 8
+
+// ErrorSnippet outputs an error message with location like ErrorAt, followed
+// by sourceLine and a caret marking col beneath it:
+//
+//     [main.go:3.10] undefined: x
+//             y := x + 1
+//                  ^
+//
+// col is a 1-based byte offset into sourceLine, as reported by go/token. Tabs
+// in sourceLine are expanded to 8-column tab stops so the caret lines up
+// however the output is displayed.
+func ErrorSnippet(e Errorer, file string, line, col int, sourceLine, msg string) {
+	if h := thelper(e); h != nil {
+		h()
+	}
+	src, caret := expandTabs(sourceLine, col)
+	ErrorAtf(e, file, line, col, "%s\n%s\n%s^", msg, src, strings.Repeat(" ", caret))
+}
+
+// expandTabs returns s with its tabs expanded, along with the 0-based display
+// column of the byte at 1-based offset col.
+func expandTabs(s string, col int) (string, int) {
+	var sb strings.Builder
+	width, caret := 0, -1
+	for i, r := range s {
+		if i >= col-1 && caret < 0 {
+			caret = width
+		}
+		if r == '\t' {
+			n := snippetTabWidth - width%snippetTabWidth
+			sb.WriteString(strings.Repeat(" ", n))
+			width += n
+		} else {
+			sb.WriteRune(r)
+			width++
+		}
+	}
+	if caret < 0 {
+		caret = width
+	}
+	return sb.String(), caret
+}
@@ -0,0 +1,35 @@
+package diag_test
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/mutility/diag"
+)
+
+func TestErrorSnippet(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		col  int
+		want string
+	}{
+		{"spaces", "    y := x + 1", 10, "    y := x + 1\n         ^"},
+		{"tab", "\ty := x + 1", 7, "        y := x + 1\n             ^"},
+		{"tabs", "\t\ty := x", 8, "                y := x\n                     ^"},
+		{"midtab", "ab\tc", 4, "ab      c\n        ^"},
+		{"unicode", "é := x", 7, "é := x\n     ^"},
+		{"end", "x", 5, "x\n ^"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sb := &strings.Builder{}
+			diag.ErrorSnippet(diag.NewWriter(sb), "a.go", 3, tt.col, tt.src, "undefined: x")
+			want := "[a.go:3." + strconv.Itoa(tt.col) + "] undefined: x\n" + tt.want + "\n"
+			if got := sb.String(); got != want {
+				t.Errorf("got\n%s\nwant\n%s", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package diag
+
+import "sync"
+
+// Speculative holds diagnostics until an operation is either committed or
+// rolled back. See NewSpeculative.
+type Speculative struct {
+	mu    sync.Mutex
+	inner Interface
+	held  []Diagnostic
+	state int // speculating, committed, or rolledBack
+}
+
+const (
+	speculating = iota
+	committed
+	rolledBack
+)
+
+// NewSpeculative creates an Interface that holds all messages until Commit
+// forwards them to inner, or Rollback discards them. Only the first call to
+// Commit or Rollback has an effect; afterwards, messages are forwarded
+// directly if committed, and discarded if rolled back.
+func NewSpeculative(inner Interface) (*Speculative, Interface) {
+	s := &Speculative{inner: inner}
+	return s, &funnel{emit: s.emit}
+}
+
+func (s *Speculative) emit(m Diagnostic) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch s.state {
+	case speculating:
+		s.held = append(s.held, m)
+	case committed:
+		forward(s.inner, m)
+	}
+}
+
+// Commit forwards the held messages to inner in the order they were issued.
+func (s *Speculative) Commit() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.state != speculating {
+		return
+	}
+	s.state = committed
+	for _, m := range s.held {
+		forward(s.inner, m)
+	}
+	s.held = nil
+}
+
+// Rollback discards the held messages.
+func (s *Speculative) Rollback() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.state != speculating {
+		return
+	}
+	s.state = rolledBack
+	s.held = nil
+}
@@ -0,0 +1,46 @@
+package diag_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mutility/diag"
+)
+
+func TestSpeculative(t *testing.T) {
+	emit := func(d diag.Interface) {
+		diag.Debug(d, "debug")
+		diag.Printf(d, "print %d", 1)
+		diag.WarningAt(d, "fn.go", 1, 2, "warning")
+		diag.Error(d, "error")
+	}
+
+	t.Run("rollback", func(t *testing.T) {
+		sb := &strings.Builder{}
+		s, d := diag.NewSpeculative(diag.NewWriterDebug(sb))
+		emit(d)
+		s.Rollback()
+		s.Commit()
+		diag.Print(d, "after")
+		if got := sb.String(); got != "" {
+			t.Errorf("got %q; want nothing", got)
+		}
+	})
+
+	t.Run("commit", func(t *testing.T) {
+		sb := &strings.Builder{}
+		s, d := diag.NewSpeculative(diag.NewWriterDebug(sb))
+		emit(d)
+		if got := sb.String(); got != "" {
+			t.Errorf("before commit: got %q; want nothing", got)
+		}
+		s.Commit()
+		s.Commit()
+		s.Rollback()
+		diag.Print(d, "after")
+		want := "debug\nprint 1\n[fn.go:1.2] warning\nerror\nafter\n"
+		if got := sb.String(); got != want {
+			t.Errorf("got %q; want %q", got, want)
+		}
+	})
+}
@@ -0,0 +1,29 @@
+package diag
+
+import "fmt"
+
+// Sprint returns the text that Print would pass to a sink for d, with the
+// values masked on d obscured, but without its trailing newline. Operands are
+// separated by spaces as with fmt.Sprintln. This suits custom sinks and test
+// helpers that need diag's rendering without writing it anywhere.
+func Sprint(d Interface, a ...interface{}) string {
+	return sprintln(mask(d).Args(a))
+}
+
+// Sprintf is like Sprint, formatting a according to format.
+func Sprintf(d Interface, format string, a ...interface{}) string {
+	m := mask(d)
+	return fmt.Sprintf(m.Format(format), m.Args(a)...)
+}
+
+// SprintAt is like Sprint, prefixed by the location formatted for d, such as
+// "[file.go:10.3] ". As with the ...At functions, the location is not masked.
+func SprintAt(d Interface, file string, line, col int, a ...interface{}) string {
+	return sprintln(fillAt(d, file, line, col, mask(d).Args(a)))
+}
+
+// SprintAtf is like Sprintf, prefixed by the location formatted for d.
+func SprintAtf(d Interface, file string, line, col int, format string, a ...interface{}) string {
+	m := mask(d)
+	return fmt.Sprintf(fillAtf(d, file, line, col, m.Format(format)), m.Args(a)...)
+}
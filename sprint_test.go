@@ -0,0 +1,45 @@
+package diag_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mutility/diag"
+)
+
+func TestSprint(t *testing.T) {
+	sb := &strings.Builder{}
+	d := diag.NewWriter(sb)
+	diag.MaskValue(d, "hunter2")
+	t.Cleanup(func() { diag.Forget(d) })
+	for _, tt := range []struct {
+		name   string
+		sprint func() string
+		write  func()
+	}{
+		{"Sprint",
+			func() string { return diag.Sprint(d, "pw", "hunter2", 1) },
+			func() { diag.Warning(d, "pw", "hunter2", 1) }},
+		{"Sprintf",
+			func() string { return diag.Sprintf(d, "pw=%s %d%%", "hunter2", 1) },
+			func() { diag.Warningf(d, "pw=%s %d%%", "hunter2", 1) }},
+		{"SprintAt",
+			func() string { return diag.SprintAt(d, "a.go", 1, 2, "pw", "hunter2") },
+			func() { diag.WarningAt(d, "a.go", 1, 2, "pw", "hunter2") }},
+		{"SprintAtf",
+			func() string { return diag.SprintAtf(d, "100%.go", 1, 0, "pw=%s", "hunter2") },
+			func() { diag.WarningAtf(d, "100%.go", 1, 0, "pw=%s", "hunter2") }},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			sb.Reset()
+			tt.write()
+			got := tt.sprint()
+			if want := strings.TrimSuffix(sb.String(), "\n"); got != want {
+				t.Errorf("got %q; want %q", got, want)
+			}
+			if strings.Contains(got, "hunter2") {
+				t.Errorf("got %q; want masked", got)
+			}
+		})
+	}
+}
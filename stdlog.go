@@ -0,0 +1,45 @@
+package diag
+
+import (
+	"log"
+	"strings"
+)
+
+// FromLog creates an Interface that writes each message through l.Output,
+// so that l's prefix and flags apply. Flags such as log.Lshortfile report the
+// caller of the diag function. Messages other than prints are prefixed with
+// their level, and located messages are preceded by their location as
+// formatted by FormatAt, such as:
+//
+//     warning: [main.go:10.3] message
+func FromLog(l *log.Logger) Interface {
+	return &funnel{emit: func(m Diagnostic) {
+		s := m.Msg
+		if loc := FormatAt(m.File, m.Line, m.Col); loc != "" {
+			s = loc + " " + s
+		}
+		if m.Level != LevelPrint {
+			s = m.Level.String() + ": " + s
+		}
+		// Skip emit and the funnel method to report the diag function's caller.
+		_ = l.Output(4, s)
+	}}
+}
+
+// ToLog creates a log.Logger that issues each line it logs to d at the given
+// severity. The Logger has no prefix or flags, leaving any decoration to d.
+func ToLog(d Interface, severity Level) *log.Logger {
+	return log.New(&logWriter{d, severity}, "", 0)
+}
+
+type logWriter struct {
+	d     Interface
+	level Level
+}
+
+// Write forwards b as a single message, without the newline added by
+// log.Logger.
+func (w *logWriter) Write(b []byte) (int, error) {
+	forward(w.d, Diagnostic{Level: w.level, Msg: strings.TrimSuffix(string(b), "\n")})
+	return len(b), nil
+}
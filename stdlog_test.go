@@ -0,0 +1,45 @@
+package diag_test
+
+import (
+	"log"
+	"strings"
+	"testing"
+
+	"github.com/mutility/diag"
+)
+
+func TestFromLog(t *testing.T) {
+	sb := &strings.Builder{}
+	d := diag.FromLog(log.New(sb, "app: ", log.Lshortfile|log.Lmsgprefix))
+	diag.Print(d, "p", 1)
+	diag.Warningf(d, "w %d", 2)
+	diag.ErrorAt(d, "a.go", 3, 4, "e")
+	diag.InfoAtf(d, "a.go", 5, 0, "i%s", "!")
+
+	want := "stdlog_test.go:14: app: p 1\n" +
+		"stdlog_test.go:15: app: warning: w 2\n" +
+		"stdlog_test.go:16: app: error: [a.go:3.4] e\n" +
+		"stdlog_test.go:17: app: info: [a.go:5] i!\n"
+	if got := sb.String(); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestToLog(t *testing.T) {
+	sb := &strings.Builder{}
+	l := diag.ToLog(diag.NewWriters(sb, sb, sb), diag.LevelWarning)
+	l.Print("one")
+	l.Printf("two\n")
+	l.Println("three", 3)
+
+	if got, want := sb.String(), "one\ntwo\nthree 3\n"; got != want {
+		t.Errorf("got %q; want %q", got, want)
+	}
+
+	sb.Reset()
+	rt := diag.FromLog(diag.ToLog(diag.NewWriter(sb), diag.LevelPrint))
+	diag.Print(rt, "round", "trip")
+	if got, want := sb.String(), "round trip\n"; got != want {
+		t.Errorf("round trip: got %q; want %q", got, want)
+	}
+}
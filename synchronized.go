@@ -0,0 +1,68 @@
+package diag
+
+import "sync"
+
+// Synchronized creates an Interface that forwards to d, serializing its
+// calls so that messages logged from concurrent goroutines are not
+// interleaved, even if d is not safe for concurrent use.
+//
+// Group holds the lock only while d begins and ends the group, and while each
+// message of fn is logged, so fn may log through the result of Synchronized
+// as well as through the Interface passed to it. Lines logged by other
+// goroutines may therefore appear among those of the group. Masks are passed
+// through to d.
+func Synchronized(d Interface) Interface {
+	return newSynchronized(d, &sync.Mutex{})
+}
+
+type synchronized struct {
+	funnel
+	inner Interface
+	mu    *sync.Mutex // shared with the Interfaces passed to Group's fn
+}
+
+func newSynchronized(d Interface, mu *sync.Mutex) *synchronized {
+	s := &synchronized{inner: d, mu: mu}
+	s.funnel.emit = s.emit
+	return s
+}
+
+func (s *synchronized) emit(m Diagnostic) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	forward(s.inner, m)
+}
+
+func (s *synchronized) Group(title string, fn func(Interface)) {
+	if h := thelper(s.inner); h != nil {
+		h()
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	Group(s.inner, title, func(g Interface) {
+		s.mu.Unlock()
+		defer s.mu.Lock()
+		fn(newSynchronized(g, s.mu))
+	})
+}
+
+// MaskValue masks v on the underlying Interface.
+func (s *synchronized) MaskValue(v string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	MaskValue(s.inner, v)
+}
+
+// UnmaskValue unmasks v on the underlying Interface.
+func (s *synchronized) UnmaskValue(v string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	UnmaskValue(s.inner, v)
+}
+
+// ClearMasks clears the masks of the underlying Interface.
+func (s *synchronized) ClearMasks() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ClearMasks(s.inner)
+}
@@ -0,0 +1,121 @@
+package diag_test
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mutility/diag"
+)
+
+// pieces writes each message in several pieces, so unsynchronized callers
+// interleave them.
+type pieces struct {
+	mu    sync.Mutex
+	lines strings.Builder
+}
+
+func (p *pieces) write(a ...interface{}) {
+	for _, s := range strings.Fields(fmt.Sprint(a...)) {
+		p.mu.Lock()
+		p.lines.WriteString(s + " ")
+		p.mu.Unlock()
+		runtime.Gosched()
+	}
+	p.mu.Lock()
+	p.lines.WriteString("\n")
+	p.mu.Unlock()
+}
+
+func (p *pieces) Debug(a ...interface{})   { p.write(a...) }
+func (p *pieces) Print(a ...interface{})   { p.write(a...) }
+func (p *pieces) Warning(a ...interface{}) { p.write(a...) }
+func (p *pieces) Error(a ...interface{})   { p.write(a...) }
+
+func TestSynchronized(t *testing.T) {
+	p := &pieces{}
+	d := diag.Synchronized(p)
+	diag.MaskValue(d, "secret")
+	t.Cleanup(func() { diag.Forget(d) })
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				switch j % 3 {
+				case 0:
+					diag.Printf(d, "g%d a b c secret", i)
+				case 1:
+					diag.WarningAt(d, "fn.go", i, j, fmt.Sprintf("g%d a b c", i))
+				case 2:
+					diag.Group(d, fmt.Sprintf("g%d", i), func(d diag.Interface) {
+						diag.Errorf(d, "g%d a b c", i)
+					})
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	lines := strings.Split(strings.TrimSuffix(p.lines.String(), "\n"), "\n")
+	if len(lines) != 20*(4+3+3*2) {
+		t.Errorf("got %d lines; want %d", len(lines), 20*(4+3+3*2))
+	}
+	for _, line := range lines {
+		f := strings.Fields(line)
+		switch {
+		case len(f) == 1 && strings.HasSuffix(f[0], ":"):
+			// group title
+		case len(f) >= 4 && f[len(f)-3] == "a" && f[len(f)-2] == "b" && f[len(f)-1] == "c":
+		case len(f) == 5 && f[4] == "***":
+		default:
+			t.Errorf("interleaved line %q", line)
+		}
+	}
+}
+
+func TestSynchronizedGroupOuter(t *testing.T) {
+	sb := &strings.Builder{}
+	d := diag.Synchronized(diag.NewWriter(sb))
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		diag.Group(d, "g", func(g diag.Interface) {
+			diag.Print(g, "inner")
+			diag.Print(d, "outer")
+			diag.Group(d, "h", func(h diag.Interface) {
+				diag.Print(h, "nested")
+			})
+		})
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("deadlocked")
+	}
+	if got, want := sb.String(), "g:\n  inner\nouter\nh:\n  nested\n"; got != want {
+		t.Errorf("got %q; want %q", got, want)
+	}
+}
+
+func TestSynchronizedMasks(t *testing.T) {
+	sb := &strings.Builder{}
+	inner := diag.NewWriter(sb)
+	d := diag.Synchronized(inner)
+	diag.MaskValue(d, "one")
+	diag.MaskValue(d, "two")
+	diag.UnmaskValue(d, "one")
+	diag.Print(d, "one two")
+	diag.ClearMasks(d)
+	diag.Print(d, "one two")
+	if got, want := sb.String(), "one ***\none two\n"; got != want {
+		t.Errorf("got %q; want %q", got, want)
+	}
+	if diag.HasMasker(inner) {
+		t.Error("masks remain on inner")
+	}
+}
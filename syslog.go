@@ -0,0 +1,74 @@
+package diag
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+// NewSyslogText creates an Interface that writes each message to w as a line
+// in the traditional syslog text format:
+//
+//     <PRI>Mmm dd hh:mm:ss host tag: message
+//
+// PRI combines facility (such as 1 for user-level messages) with the
+// severity: 3 for errors, 4 for warnings, 6 for printed and informational
+// messages, and 7 for debug messages. Levels more severe than LevelError are
+// reported as errors, and those less severe than LevelDebug as debug
+// messages. Locations from ...At and ...Atf variants are formatted with
+// FormatAt at the start of the message.
+//
+// This targets log files rather than a syslog daemon. The timestamp and host
+// can be overridden with WithClock and WithHostname; write errors are passed
+// to the handler supplied by WithErrorHandler.
+func NewSyslogText(w io.Writer, facility int, tag string, opts ...Option) Interface {
+	s := &syslogText{w: w, facility: facility, tag: tag, opts: newOptions(opts)}
+	if s.opts.hostname != nil {
+		s.host = *s.opts.hostname
+	} else if host, err := os.Hostname(); err == nil {
+		s.host = host
+	} else {
+		s.host = "-"
+	}
+	return &funnel{emit: s.emit}
+}
+
+type syslogText struct {
+	w        io.Writer
+	facility int
+	tag      string
+	host     string
+	opts     options
+}
+
+// syslogSeverities maps levels to syslog severities.
+var syslogSeverities = map[Level]int{
+	LevelDebug:   7,
+	LevelInfo:    6,
+	LevelPrint:   6,
+	LevelWarning: 4,
+	LevelError:   3,
+}
+
+// syslogSeverity returns the syslog severity for l, clamping levels outside
+// the known range to the nearest one.
+func syslogSeverity(l Level) int {
+	switch {
+	case l > LevelError:
+		l = LevelError
+	case l < LevelDebug:
+		l = LevelDebug
+	}
+	return syslogSeverities[l]
+}
+
+func (s *syslogText) emit(m Diagnostic) {
+	msg := m.Msg
+	if loc := FormatAt(m.File, m.Line, m.Col); loc != "" {
+		msg = loc + " " + msg
+	}
+	pri := s.facility*8 + syslogSeverity(m.Level)
+	ts := s.opts.now().Format("Jan _2 15:04:05")
+	_, err := fmt.Fprintf(s.w, "<%d>%s %s %s: %s\n", pri, ts, s.host, s.tag, msg)
+	s.opts.error(err)
+}
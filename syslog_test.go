@@ -0,0 +1,56 @@
+package diag_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mutility/diag"
+)
+
+func TestSyslogText(t *testing.T) {
+	clock := diag.NewFakeClock(time.Date(2021, 3, 4, 5, 6, 7, 0, time.UTC))
+	sb := &strings.Builder{}
+	d := diag.NewSyslogText(sb, 1, "tool", diag.WithClock(clock), diag.WithHostname("host"))
+	diag.Debug(d, "debug")
+	diag.Print(d, "print")
+	diag.Warningf(d, "warning %d", 1)
+	clock.Advance(24 * time.Hour)
+	diag.ErrorAt(d, "fn.go", 10, 3, "error")
+
+	want := "<15>Mar  4 05:06:07 host tool: debug\n" +
+		"<14>Mar  4 05:06:07 host tool: print\n" +
+		"<12>Mar  4 05:06:07 host tool: warning 1\n" +
+		"<11>Mar  5 05:06:07 host tool: [fn.go:10.3] error\n"
+	if got := sb.String(); got != want {
+		t.Errorf("got %q; want %q", got, want)
+	}
+}
+
+func TestSyslogTextFacility(t *testing.T) {
+	sb := &strings.Builder{}
+	d := diag.NewSyslogText(sb, 16, "tool", diag.WithHostname("host"))
+	diag.Error(d, "error")
+	if got := sb.String(); !strings.HasPrefix(got, "<131>") {
+		t.Errorf("got %q; want <131> prefix for local0.err", got)
+	}
+}
+
+func TestSyslogSeverity(t *testing.T) {
+	for _, tt := range []struct {
+		level diag.Level
+		want  int
+	}{
+		{diag.LevelNone, 7},
+		{diag.LevelDebug, 7},
+		{diag.LevelInfo, 6},
+		{diag.LevelPrint, 6},
+		{diag.LevelWarning, 4},
+		{diag.LevelError, 3},
+		{diag.LevelError + 1, 3},
+	} {
+		if got := diag.SyslogSeverity(tt.level); got != tt.want {
+			t.Errorf("%v: got %d; want %d", tt.level, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,235 @@
+package diag
+
+// Tee creates an Interface that forwards each call to every one of ds. Each
+// call is reissued in its original shape, so that each target renders it in
+// its own way: a located warning reaches a structured target with its file,
+// line, and column intact, and a text target with its bracketed location.
+// Nil targets are skipped.
+//
+// Group opens the group on every target, and runs fn once against a Tee of
+// the targets' groups. MaskValue masks the value on every target.
+func Tee(ds ...Interface) Interface {
+	return &tee{ds}
+}
+
+type tee struct {
+	ds []Interface
+}
+
+func (t *tee) Debug(a ...interface{}) {
+	for _, d := range t.ds {
+		if h := thelper(d); h != nil {
+			h()
+		}
+		Debug(d, a...)
+	}
+}
+
+func (t *tee) Debugf(format string, a ...interface{}) {
+	for _, d := range t.ds {
+		if h := thelper(d); h != nil {
+			h()
+		}
+		Debugf(d, format, a...)
+	}
+}
+
+func (t *tee) DebugAt(file string, line, col int, a ...interface{}) {
+	for _, d := range t.ds {
+		if h := thelper(d); h != nil {
+			h()
+		}
+		DebugAt(d, file, line, col, a...)
+	}
+}
+
+func (t *tee) DebugAtf(file string, line, col int, format string, a ...interface{}) {
+	for _, d := range t.ds {
+		if h := thelper(d); h != nil {
+			h()
+		}
+		DebugAtf(d, file, line, col, format, a...)
+	}
+}
+
+func (t *tee) Info(a ...interface{}) {
+	for _, d := range t.ds {
+		if h := thelper(d); h != nil {
+			h()
+		}
+		Info(d, a...)
+	}
+}
+
+func (t *tee) Infof(format string, a ...interface{}) {
+	for _, d := range t.ds {
+		if h := thelper(d); h != nil {
+			h()
+		}
+		Infof(d, format, a...)
+	}
+}
+
+func (t *tee) InfoAt(file string, line, col int, a ...interface{}) {
+	for _, d := range t.ds {
+		if h := thelper(d); h != nil {
+			h()
+		}
+		InfoAt(d, file, line, col, a...)
+	}
+}
+
+func (t *tee) InfoAtf(file string, line, col int, format string, a ...interface{}) {
+	for _, d := range t.ds {
+		if h := thelper(d); h != nil {
+			h()
+		}
+		InfoAtf(d, file, line, col, format, a...)
+	}
+}
+
+func (t *tee) Print(a ...interface{}) {
+	for _, d := range t.ds {
+		if h := thelper(d); h != nil {
+			h()
+		}
+		Print(d, a...)
+	}
+}
+
+func (t *tee) Printf(format string, a ...interface{}) {
+	for _, d := range t.ds {
+		if h := thelper(d); h != nil {
+			h()
+		}
+		Printf(d, format, a...)
+	}
+}
+
+func (t *tee) PrintAt(file string, line, col int, a ...interface{}) {
+	for _, d := range t.ds {
+		if h := thelper(d); h != nil {
+			h()
+		}
+		PrintAt(d, file, line, col, a...)
+	}
+}
+
+func (t *tee) PrintAtf(file string, line, col int, format string, a ...interface{}) {
+	for _, d := range t.ds {
+		if h := thelper(d); h != nil {
+			h()
+		}
+		PrintAtf(d, file, line, col, format, a...)
+	}
+}
+
+func (t *tee) Warning(a ...interface{}) {
+	for _, d := range t.ds {
+		if h := thelper(d); h != nil {
+			h()
+		}
+		Warning(d, a...)
+	}
+}
+
+func (t *tee) Warningf(format string, a ...interface{}) {
+	for _, d := range t.ds {
+		if h := thelper(d); h != nil {
+			h()
+		}
+		Warningf(d, format, a...)
+	}
+}
+
+func (t *tee) WarningAt(file string, line, col int, a ...interface{}) {
+	for _, d := range t.ds {
+		if h := thelper(d); h != nil {
+			h()
+		}
+		WarningAt(d, file, line, col, a...)
+	}
+}
+
+func (t *tee) WarningAtf(file string, line, col int, format string, a ...interface{}) {
+	for _, d := range t.ds {
+		if h := thelper(d); h != nil {
+			h()
+		}
+		WarningAtf(d, file, line, col, format, a...)
+	}
+}
+
+func (t *tee) Error(a ...interface{}) {
+	for _, d := range t.ds {
+		if h := thelper(d); h != nil {
+			h()
+		}
+		Error(d, a...)
+	}
+}
+
+func (t *tee) Errorf(format string, a ...interface{}) {
+	for _, d := range t.ds {
+		if h := thelper(d); h != nil {
+			h()
+		}
+		Errorf(d, format, a...)
+	}
+}
+
+func (t *tee) ErrorAt(file string, line, col int, a ...interface{}) {
+	for _, d := range t.ds {
+		if h := thelper(d); h != nil {
+			h()
+		}
+		ErrorAt(d, file, line, col, a...)
+	}
+}
+
+func (t *tee) ErrorAtf(file string, line, col int, format string, a ...interface{}) {
+	for _, d := range t.ds {
+		if h := thelper(d); h != nil {
+			h()
+		}
+		ErrorAtf(d, file, line, col, format, a...)
+	}
+}
+
+func (t *tee) Group(title string, fn func(Interface)) {
+	groupAll(t.ds, nil, title, fn)
+}
+
+// groupAll opens a group titled title on each of ds in turn, and then runs fn
+// against a Tee of the groups gs opened so far.
+func groupAll(ds, gs []Interface, title string, fn func(Interface)) {
+	if len(ds) == 0 {
+		fn(Tee(gs...))
+		return
+	}
+	if isNil(ds[0]) {
+		groupAll(ds[1:], gs, title, fn)
+		return
+	}
+	Group(ds[0], title, func(g Interface) {
+		groupAll(ds[1:], append(gs[:len(gs):len(gs)], g), title, fn)
+	})
+}
+
+func (t *tee) MaskValue(v string) {
+	for _, d := range t.ds {
+		MaskValue(d, v)
+	}
+}
+
+func (t *tee) UnmaskValue(v string) {
+	for _, d := range t.ds {
+		UnmaskValue(d, v)
+	}
+}
+
+func (t *tee) ClearMasks() {
+	for _, d := range t.ds {
+		ClearMasks(d)
+	}
+}
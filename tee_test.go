@@ -0,0 +1,81 @@
+package diag_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mutility/diag"
+	"github.com/mutility/diag/testdiag"
+)
+
+func TestTee(t *testing.T) {
+	sb := &strings.Builder{}
+	structured := testdiag.Capture(t)
+	d := diag.Tee(structured, diag.NewWriter(sb), nil)
+	diag.MaskValue(d, "hunter2")
+	t.Cleanup(func() { diag.Forget(d) })
+	diag.WarningAt(d, "a.go", 1, 2, "careful")
+	diag.ErrorAtf(d, "b.go", 3, 4, "bad %s", "hunter2")
+	diag.Print(d, "plain")
+
+	want := []testdiag.Entry{
+		{Level: diag.LevelWarning, File: "a.go", Line: 1, Col: 2, Msg: "careful"},
+		{Level: diag.LevelError, File: "b.go", Line: 3, Col: 4, Msg: "bad ***"},
+		{Level: diag.LevelPrint, Msg: "plain"},
+	}
+	got := structured.Entries()
+	if len(got) != len(want) {
+		t.Fatalf("structured: got %v; want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("structured[%d]: got %v; want %v", i, got[i], want[i])
+		}
+	}
+
+	wantText := "[a.go:1.2] careful\n[b.go:3.4] bad ***\nplain\n"
+	if got := sb.String(); got != wantText {
+		t.Errorf("text: got %q; want %q", got, wantText)
+	}
+}
+
+func TestTeeGroup(t *testing.T) {
+	var text, gha strings.Builder
+	d := diag.Tee(diag.NewWriter(&text), nil, diag.NewGitHubActions(&gha))
+	diag.MaskValue(d, "hunter2")
+	t.Cleanup(func() { diag.Forget(d) })
+	diag.Group(d, "outer", func(d diag.Interface) {
+		diag.Warning(d, "w")
+		diag.Group(d, "inner", func(d diag.Interface) {
+			diag.ErrorAtf(d, "a.go", 1, 0, "bad %s", "hunter2")
+		})
+	})
+	diag.Debug(d, "hunter2")
+
+	if got, want := text.String(), "outer:\n  w\n  inner:\n[a.go:1]     bad ***\n"; got != want {
+		t.Errorf("text: got %q; want %q", got, want)
+	}
+	if got, want := gha.String(), "::group::outer\n::warning::w\ninner:\n::error file=a.go,line=1::bad ***\n::endgroup::\n::debug::***\n"; got != want {
+		t.Errorf("actions: got %q; want %q", got, want)
+	}
+}
+
+func TestTeeMasks(t *testing.T) {
+	a, b := &strings.Builder{}, &strings.Builder{}
+	da, db := diag.NewWriter(a), diag.NewWriter(b)
+	d := diag.Tee(da, db, nil)
+	diag.MaskValue(d, "one")
+	diag.MaskValue(d, "two")
+	diag.UnmaskValue(d, "one")
+	diag.Print(d, "one two")
+	diag.ClearMasks(d)
+	diag.Print(d, "one two")
+	for name, sb := range map[string]*strings.Builder{"a": a, "b": b} {
+		if got, want := sb.String(), "one ***\none two\n"; got != want {
+			t.Errorf("%s: got %q; want %q", name, got, want)
+		}
+	}
+	if diag.HasMasker(da) || diag.HasMasker(db) {
+		t.Error("masks remain on targets")
+	}
+}
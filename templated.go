@@ -0,0 +1,55 @@
+package diag
+
+import (
+	"bytes"
+	"io"
+	"text/template"
+	"time"
+)
+
+// TemplateData is the value passed to the template used by NewTemplated.
+type TemplateData struct {
+	Diagnostic
+	Time time.Time
+}
+
+// DefaultTemplate is used by NewTemplated when no template is supplied. It
+// renders the level, the location in the style of FormatAtBracket, and the
+// message.
+var DefaultTemplate = template.Must(template.New("diag").Parse(
+	`{{.Level}}:{{with .File}} [{{.}}{{with $.Line}}:{{.}}{{with $.Col}}.{{.}}{{end}}{{end}}]{{end}} {{.Msg}}`,
+))
+
+// NewTemplated creates an Interface that renders each message by executing
+// tmpl with a TemplateData, and writes the result followed by a newline to w.
+// If tmpl is nil, DefaultTemplate is used.
+//
+// Time is supplied by the Clock passed to WithClock, if any, and otherwise by
+// the system clock.
+//
+// Errors executing tmpl or writing to w are passed to the handler supplied by
+// WithErrorHandler, if any; the message is not written.
+func NewTemplated(w io.Writer, tmpl *template.Template, opts ...Option) Interface {
+	if tmpl == nil {
+		tmpl = DefaultTemplate
+	}
+	t := &templated{w: w, tmpl: tmpl, opts: newOptions(opts)}
+	return &funnel{emit: t.emit}
+}
+
+type templated struct {
+	w    io.Writer
+	tmpl *template.Template
+	opts options
+}
+
+func (t *templated) emit(m Diagnostic) {
+	var buf bytes.Buffer
+	if err := t.tmpl.Execute(&buf, TemplateData{m, t.opts.now()}); err != nil {
+		t.opts.error(err)
+		return
+	}
+	buf.WriteByte('\n')
+	_, err := t.w.Write(buf.Bytes())
+	t.opts.error(err)
+}
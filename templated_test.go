@@ -0,0 +1,82 @@
+package diag_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"text/template"
+	"time"
+
+	"github.com/mutility/diag"
+)
+
+func TestTemplated(t *testing.T) {
+	tmpl := template.Must(template.New("t").Parse(`<{{.Level}}|{{.File}}|{{.Line}}|{{.Col}}|{{.Msg}}>`))
+	sb := &strings.Builder{}
+	d := diag.NewTemplated(sb, tmpl)
+	diag.MaskValue(d, "secret")
+	t.Cleanup(func() { diag.Forget(d) })
+
+	diag.Debug(d, "a", "b")
+	diag.Printf(d, "%s-%d", "c", 1)
+	diag.WarningAt(d, "fn.go", 10, 3, "secret", "here")
+	diag.ErrorAtf(d, "fn.go", 10, 0, "%q", "secret")
+
+	want := "<debug||0|0|a b>\n" +
+		"<print||0|0|c-1>\n" +
+		"<warning|fn.go|10|3|*** here>\n" +
+		"<error|fn.go|10|0|\"***\">\n"
+	if got := sb.String(); got != want {
+		t.Errorf("got %q; want %q", got, want)
+	}
+}
+
+func TestTemplatedDefault(t *testing.T) {
+	sb := &strings.Builder{}
+	d := diag.NewTemplated(sb, nil)
+	diag.Warning(d, "plain")
+	diag.ErrorAt(d, "fn.go", 10, 3, "located")
+	diag.ErrorAt(d, "fn.go", 0, 3, "noline")
+
+	want := "warning: plain\n" +
+		"error: [fn.go:10.3] located\n" +
+		"error: [fn.go] noline\n"
+	if got := sb.String(); got != want {
+		t.Errorf("got %q; want %q", got, want)
+	}
+}
+
+func TestTemplatedError(t *testing.T) {
+	tmpl := template.Must(template.New("t").Parse(`{{.Missing}}`))
+	sb := &strings.Builder{}
+	var errs []error
+	d := diag.NewTemplated(sb, tmpl, diag.WithErrorHandler(func(err error) { errs = append(errs, err) }))
+	diag.Error(d, "boom")
+	if len(errs) != 1 {
+		t.Fatalf("got %d errors; want 1", len(errs))
+	}
+	var execErr template.ExecError
+	if !errors.As(errs[0], &execErr) {
+		t.Errorf("got %T; want template.ExecError", errs[0])
+	}
+	if got := sb.String(); got != "" {
+		t.Errorf("wrote %q; want nothing", got)
+	}
+}
+
+func TestTemplatedClock(t *testing.T) {
+	tmpl := template.Must(template.New("t").Parse(`{{.Time.Format "15:04:05"}} {{.Msg}}`))
+	clock := diag.NewFakeClock(time.Date(2021, 3, 4, 5, 6, 7, 0, time.UTC))
+	sb := &strings.Builder{}
+	d := diag.NewTemplated(sb, tmpl, diag.WithClock(clock))
+	diag.Print(d, "first")
+	clock.Advance(90 * time.Second)
+	diag.Print(d, "second")
+	clock.Set(time.Date(2021, 3, 4, 23, 0, 0, 0, time.UTC))
+	diag.Print(d, "third")
+
+	want := "05:06:07 first\n05:07:37 second\n23:00:00 third\n"
+	if got := sb.String(); got != want {
+		t.Errorf("got %q; want %q", got, want)
+	}
+}
@@ -0,0 +1,202 @@
+package testdiag
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/mutility/diag"
+)
+
+// Entry is a diagnostic recorded by a Capturer.
+type Entry = diag.Diagnostic
+
+// Capturer is a diag.Interface that records each diagnostic for later
+// assertions.
+type Capturer struct {
+	tb      t
+	mu      sync.Mutex
+	entries []Entry
+	mirror  bool
+}
+
+// Capture returns a Capturer that records diagnostics issued during a test.
+func Capture(tb t) *Capturer {
+	return &Capturer{tb: tb}
+}
+
+// Entries returns a copy of the diagnostics recorded so far.
+func (c *Capturer) Entries() []Entry {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]Entry(nil), c.entries...)
+}
+
+// Mirror controls whether each diagnostic is also logged to the test as it
+// is recorded. It is off by default.
+func (c *Capturer) Mirror(on bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.mirror = on
+}
+
+// Debugs returns the debug entries recorded so far.
+func (c *Capturer) Debugs() []Entry { return c.level(diag.LevelDebug) }
+
+// Infos returns the info entries recorded so far.
+func (c *Capturer) Infos() []Entry { return c.level(diag.LevelInfo) }
+
+// Prints returns the print entries recorded so far.
+func (c *Capturer) Prints() []Entry { return c.level(diag.LevelPrint) }
+
+// Warnings returns the warning entries recorded so far.
+func (c *Capturer) Warnings() []Entry { return c.level(diag.LevelWarning) }
+
+// Errors returns the error entries recorded so far.
+func (c *Capturer) Errors() []Entry { return c.level(diag.LevelError) }
+
+func (c *Capturer) level(l diag.Level) []Entry {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var entries []Entry
+	for _, e := range c.entries {
+		if e.Level == l {
+			entries = append(entries, e)
+		}
+	}
+	return entries
+}
+
+// errorT is the subset of testing.TB needed to report failed assertions.
+type errorT interface {
+	Helper()
+	Errorf(string, ...interface{})
+}
+
+// AssertOrder verifies that want appear among the recorded entries in the
+// given order, although other entries may come before, between, or after
+// them. It reports the first entry of want that is missing or out of order.
+func (c *Capturer) AssertOrder(tb errorT, want ...Entry) {
+	tb.Helper()
+	got := c.Entries()
+	next := 0 // index in got after the last match
+	for i, w := range want {
+		found := -1
+		for j := next; j < len(got); j++ {
+			if got[j] == w {
+				found = j
+				break
+			}
+		}
+		if found >= 0 {
+			next = found + 1
+			continue
+		}
+		for j := 0; j < next; j++ {
+			if got[j] == w {
+				tb.Errorf("entry %d %s: recorded at %d, before entry %d at %d", i, format(w), j, i-1, next-1)
+				return
+			}
+		}
+		tb.Errorf("entry %d %s: not recorded", i, format(w))
+		return
+	}
+}
+
+func format(e Entry) string {
+	return fmt.Sprintf("%s %q", e.Level, strings.TrimSpace(diag.FormatAt(e.File, e.Line, e.Col)+" "+e.Msg))
+}
+
+func (c *Capturer) record(e Entry) {
+	c.tb.Helper()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = append(c.entries, e)
+	if c.mirror {
+		c.tb.Log(format(e))
+	}
+}
+
+func (c *Capturer) Debug(a ...interface{}) {
+	c.record(Entry{Level: diag.LevelDebug, Msg: sprintln(a)})
+}
+
+func (c *Capturer) Debugf(format string, a ...interface{}) {
+	c.record(Entry{Level: diag.LevelDebug, Msg: fmt.Sprintf(format, a...)})
+}
+
+func (c *Capturer) DebugAt(file string, line, col int, a ...interface{}) {
+	c.record(Entry{Level: diag.LevelDebug, File: file, Line: line, Col: col, Msg: sprintln(a)})
+}
+
+func (c *Capturer) DebugAtf(file string, line, col int, format string, a ...interface{}) {
+	c.record(Entry{Level: diag.LevelDebug, File: file, Line: line, Col: col, Msg: fmt.Sprintf(format, a...)})
+}
+
+func (c *Capturer) Info(a ...interface{}) {
+	c.record(Entry{Level: diag.LevelInfo, Msg: sprintln(a)})
+}
+
+func (c *Capturer) Infof(format string, a ...interface{}) {
+	c.record(Entry{Level: diag.LevelInfo, Msg: fmt.Sprintf(format, a...)})
+}
+
+func (c *Capturer) InfoAt(file string, line, col int, a ...interface{}) {
+	c.record(Entry{Level: diag.LevelInfo, File: file, Line: line, Col: col, Msg: sprintln(a)})
+}
+
+func (c *Capturer) InfoAtf(file string, line, col int, format string, a ...interface{}) {
+	c.record(Entry{Level: diag.LevelInfo, File: file, Line: line, Col: col, Msg: fmt.Sprintf(format, a...)})
+}
+
+func (c *Capturer) Print(a ...interface{}) {
+	c.record(Entry{Level: diag.LevelPrint, Msg: sprintln(a)})
+}
+
+func (c *Capturer) Printf(format string, a ...interface{}) {
+	c.record(Entry{Level: diag.LevelPrint, Msg: fmt.Sprintf(format, a...)})
+}
+
+func (c *Capturer) PrintAt(file string, line, col int, a ...interface{}) {
+	c.record(Entry{Level: diag.LevelPrint, File: file, Line: line, Col: col, Msg: sprintln(a)})
+}
+
+func (c *Capturer) PrintAtf(file string, line, col int, format string, a ...interface{}) {
+	c.record(Entry{Level: diag.LevelPrint, File: file, Line: line, Col: col, Msg: fmt.Sprintf(format, a...)})
+}
+
+func (c *Capturer) Warning(a ...interface{}) {
+	c.record(Entry{Level: diag.LevelWarning, Msg: sprintln(a)})
+}
+
+func (c *Capturer) Warningf(format string, a ...interface{}) {
+	c.record(Entry{Level: diag.LevelWarning, Msg: fmt.Sprintf(format, a...)})
+}
+
+func (c *Capturer) WarningAt(file string, line, col int, a ...interface{}) {
+	c.record(Entry{Level: diag.LevelWarning, File: file, Line: line, Col: col, Msg: sprintln(a)})
+}
+
+func (c *Capturer) WarningAtf(file string, line, col int, format string, a ...interface{}) {
+	c.record(Entry{Level: diag.LevelWarning, File: file, Line: line, Col: col, Msg: fmt.Sprintf(format, a...)})
+}
+
+func (c *Capturer) Error(a ...interface{}) {
+	c.record(Entry{Level: diag.LevelError, Msg: sprintln(a)})
+}
+
+func (c *Capturer) Errorf(format string, a ...interface{}) {
+	c.record(Entry{Level: diag.LevelError, Msg: fmt.Sprintf(format, a...)})
+}
+
+func (c *Capturer) ErrorAt(file string, line, col int, a ...interface{}) {
+	c.record(Entry{Level: diag.LevelError, File: file, Line: line, Col: col, Msg: sprintln(a)})
+}
+
+func (c *Capturer) ErrorAtf(file string, line, col int, format string, a ...interface{}) {
+	c.record(Entry{Level: diag.LevelError, File: file, Line: line, Col: col, Msg: fmt.Sprintf(format, a...)})
+}
+
+func sprintln(a []interface{}) string {
+	return strings.TrimSuffix(fmt.Sprintln(a...), "\n")
+}
@@ -0,0 +1,88 @@
+package testdiag_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/mutility/diag"
+	"github.com/mutility/diag/testdiag"
+)
+
+type fakeTB struct{ errors []string }
+
+func (f *fakeTB) Helper()            {}
+func (f *fakeTB) Log(...interface{}) {}
+func (f *fakeTB) Errorf(format string, a ...interface{}) {
+	f.errors = append(f.errors, fmt.Sprintf(format, a...))
+}
+
+func TestAssertOrder(t *testing.T) {
+	c := testdiag.Capture(t)
+	diag.Debug(c, "start")
+	diag.WarningAt(c, "fn.go", 1, 2, "careful")
+	diag.Print(c, "between")
+	diag.Errorf(c, "failed: %d", 3)
+
+	warn := testdiag.Entry{Level: diag.LevelWarning, File: "fn.go", Line: 1, Col: 2, Msg: "careful"}
+	fail := testdiag.Entry{Level: diag.LevelError, Msg: "failed: 3"}
+	missing := testdiag.Entry{Level: diag.LevelError, Msg: "missing"}
+
+	for _, tt := range []struct {
+		name string
+		want []testdiag.Entry
+		errs []string
+	}{
+		{"empty", nil, nil},
+		{"ordered", []testdiag.Entry{warn, fail}, nil},
+		{"reversed", []testdiag.Entry{fail, warn}, []string{
+			`entry 1 warning "[fn.go:1.2] careful": recorded at 1, before entry 0 at 3`,
+		}},
+		{"missing", []testdiag.Entry{warn, missing, fail}, []string{
+			`entry 1 error "missing": not recorded`,
+		}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			tb := &fakeTB{}
+			c.AssertOrder(tb, tt.want...)
+			if fmt.Sprint(tb.errors) != fmt.Sprint(tt.errs) {
+				t.Errorf("got %q; want %q", tb.errors, tt.errs)
+			}
+		})
+	}
+}
+
+func TestCaptureByLevel(t *testing.T) {
+	tb := &logTB{}
+	c := testdiag.Capture(tb)
+	diag.Print(c, "quiet")
+	c.Mirror(true)
+	diag.WarningAt(c, "config.go", 12, 5, "unknown key", "colour")
+	diag.Warningf(c, "deprecated: %s", "--foo")
+	diag.ErrorAt(c, "config.go", 20, 0, "bad value")
+
+	want := []testdiag.Entry{
+		{Level: diag.LevelWarning, File: "config.go", Line: 12, Col: 5, Msg: "unknown key colour"},
+		{Level: diag.LevelWarning, Msg: "deprecated: --foo"},
+	}
+	if got := c.Warnings(); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("Warnings: got %v; want %v", got, want)
+	}
+	if got := c.Errors(); len(got) != 1 || got[0].Msg != "bad value" {
+		t.Errorf("Errors: got %v", got)
+	}
+	if got := c.Debugs(); got != nil {
+		t.Errorf("Debugs: got %v; want none", got)
+	}
+	if got := len(c.Prints()); got != 1 {
+		t.Errorf("Prints: got %d; want 1", got)
+	}
+
+	wantLogs := []string{
+		"warning \"[config.go:12.5] unknown key colour\"\n",
+		"warning \"deprecated: --foo\"\n",
+		"error \"[config.go:20] bad value\"\n",
+	}
+	if fmt.Sprintf("%q", tb.logs) != fmt.Sprintf("%q", wantLogs) {
+		t.Errorf("logs: got %q; want %q", tb.logs, wantLogs)
+	}
+}
@@ -0,0 +1,91 @@
+package testdiag
+
+import (
+	"fmt"
+	"sync/atomic"
+
+	"github.com/mutility/diag"
+)
+
+// failT is the subset of testing.TB needed to log and fail. It widens t with
+// Errorf, which *testing.T and *testing.B satisfy.
+type failT interface {
+	t
+	Errorf(string, ...interface{})
+}
+
+// FailAt returns a diag.Interface that fails tb for each error, and logs
+// other levels like Interface. Errors with a location are reported with a
+// file:line: or file:line:col: prefix, so the failure points at the
+// diagnostic's location rather than the line of the test.
+func FailAt(tb failT) diag.Interface {
+	return failAt{testDiag{tb}, tb, new(int32), diag.FormatAtGNU}
+}
+
+// Strict returns a diag.Interface that fails tb for each error, so that
+// errors emitted by the code under test fail the test. Unlike FailAt, errors
+// keep the location prefix from diag.FormatAt that Interface logs, so failures
+// read like the rest of the test's output. Tests that expect errors can pass
+// the result to AllowErrors.
+func Strict(tb failT) diag.Interface {
+	return failAt{testDiag{tb}, tb, new(int32), diag.FormatAt}
+}
+
+// AllowErrors controls whether d, as returned by Strict or FailAt, fails the
+// test for errors. While allowed, errors are logged like other levels. It
+// does nothing for other Interfaces.
+func AllowErrors(d diag.Interface, allow bool) {
+	if f, ok := d.(failAt); ok {
+		var v int32
+		if allow {
+			v = 1
+		}
+		atomic.StoreInt32(f.allow, v)
+	}
+}
+
+type failAt struct {
+	testDiag
+	tb    failT
+	allow *int32 // nonzero if errors are logged rather than failing
+	at    func(file string, line, col int) string
+}
+
+// fail reports msg as a failure, or logs it if errors are allowed.
+func (d failAt) fail(msg string) {
+	d.tb.Helper()
+	if atomic.LoadInt32(d.allow) != 0 {
+		d.tb.Log(msg)
+	} else {
+		d.tb.Errorf("%s", msg)
+	}
+}
+
+func (d failAt) Error(args ...interface{}) {
+	d.tb.Helper()
+	d.fail(sprintln(args))
+}
+
+func (d failAt) Errorf(format string, args ...interface{}) {
+	d.tb.Helper()
+	d.fail(fmt.Sprintf(format, args...))
+}
+
+func (d failAt) ErrorAt(file string, line, col int, args ...interface{}) {
+	d.tb.Helper()
+	d.fail(d.location(file, line, col) + sprintln(args))
+}
+
+func (d failAt) ErrorAtf(file string, line, col int, format string, args ...interface{}) {
+	d.tb.Helper()
+	d.fail(d.location(file, line, col) + fmt.Sprintf(format, args...))
+}
+
+// location renders file, line, and col with d.at, followed by a space if it
+// is not empty.
+func (d failAt) location(file string, line, col int) string {
+	if loc := d.at(file, line, col); loc != "" {
+		return loc + " "
+	}
+	return ""
+}
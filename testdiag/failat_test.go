@@ -0,0 +1,61 @@
+package testdiag_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/mutility/diag"
+	"github.com/mutility/diag/testdiag"
+)
+
+type failTB struct {
+	fakeTB
+	logs []string
+}
+
+func (f *failTB) Log(a ...interface{}) { f.logs = append(f.logs, fmt.Sprint(a...)) }
+
+func TestFailAt(t *testing.T) {
+	tb := &failTB{}
+	d := testdiag.FailAt(tb)
+	diag.ErrorAt(d, "parse.go", 12, 4, "unexpected", "token")
+	diag.ErrorAtf(d, "parse.go", 13, 0, "missing %s", "brace")
+	diag.Error(d, "plain")
+	diag.Errorf(d, "plain %d", 2)
+	diag.WarningAt(d, "parse.go", 1, 1, "only logged")
+	diag.Print(d, "also logged")
+
+	want := []string{
+		"parse.go:12:4: unexpected token",
+		"parse.go:13: missing brace",
+		"plain",
+		"plain 2",
+	}
+	if fmt.Sprint(tb.errors) != fmt.Sprint(want) {
+		t.Errorf("errors: got %q; want %q", tb.errors, want)
+	}
+	if len(tb.logs) != 2 {
+		t.Errorf("logs: got %q; want 2 entries", tb.logs)
+	}
+}
+
+func TestStrict(t *testing.T) {
+	tb := &failTB{}
+	d := testdiag.Strict(tb)
+	diag.Error(d, "fails")
+	testdiag.AllowErrors(d, true)
+	diag.ErrorAt(d, "a.go", 1, 0, "expected")
+	diag.Warning(d, "warned")
+	testdiag.AllowErrors(d, false)
+	diag.Errorf(d, "fails %d", 2)
+	testdiag.AllowErrors(testdiag.Interface(tb), true) // ignored
+
+	diag.ErrorAt(d, "b.go", 2, 3, "fails")
+
+	if want := []string{"fails", "fails 2", "[b.go:2.3] fails"}; fmt.Sprint(tb.errors) != fmt.Sprint(want) {
+		t.Errorf("errors: got %q; want %q", tb.errors, want)
+	}
+	if want := []string{"[a.go:1] expected", "warned"}; fmt.Sprint(tb.logs) != fmt.Sprint(want) {
+		t.Errorf("logs: got %q; want %q", tb.logs, want)
+	}
+}
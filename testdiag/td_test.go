@@ -1,6 +1,7 @@
 package testdiag_test
 
 import (
+	"fmt"
 	"testing"
 
 	"github.com/mutility/diag"
@@ -19,3 +20,47 @@ func TestExample(t *testing.T) {
 	})
 	diag.Print(td, "hahahahaha") // logs "******ha"
 }
+
+type attrTB struct {
+	attrs [][2]string
+	logs  []string
+}
+
+func (a *attrTB) Helper()                 {}
+func (a *attrTB) Log(args ...interface{}) { a.logs = append(a.logs, fmt.Sprintln(args...)) }
+func (a *attrTB) Attr(key, value string)  { a.attrs = append(a.attrs, [2]string{key, value}) }
+
+type logTB struct{ logs []string }
+
+func (l *logTB) Helper()                 {}
+func (l *logTB) Log(args ...interface{}) { l.logs = append(l.logs, fmt.Sprintln(args...)) }
+
+func TestAttr(t *testing.T) {
+	tb := &attrTB{}
+	td := testdiag.Interface(tb)
+	diag.ErrorAt(td, "fn.go", 10, 3, "located")
+	diag.WarningAtf(td, "fn.go", 12, 0, "%s", "formatted")
+	diag.Error(td, "plain")
+	diag.ErrorAt(td, "bad\nname.go", 1, 2, "unattributed")
+
+	wantAttrs := [][2]string{{"file", "fn.go"}, {"line", "10"}, {"col", "3"}, {"file", "fn.go"}, {"line", "12"}}
+	if fmt.Sprint(tb.attrs) != fmt.Sprint(wantAttrs) {
+		t.Errorf("attrs: got %q; want %q", tb.attrs, wantAttrs)
+	}
+	wantLogs := []string{"[fn.go:10.3] located\n", "[fn.go:12] formatted\n", "plain\n", "[bad\nname.go:1.2] unattributed\n"}
+	if fmt.Sprint(tb.logs) != fmt.Sprint(wantLogs) {
+		t.Errorf("logs: got %q; want %q", tb.logs, wantLogs)
+	}
+}
+
+func TestNoAttr(t *testing.T) {
+	tb := &logTB{}
+	td := testdiag.Interface(tb)
+	diag.ErrorAt(td, "fn.go", 10, 3, "located")
+	diag.WarningAtf(td, "fn.go", 12, 0, "%s", "formatted")
+
+	wantLogs := []string{"[fn.go:10.3] located\n", "[fn.go:12] formatted\n"}
+	if fmt.Sprint(tb.logs) != fmt.Sprint(wantLogs) {
+		t.Errorf("logs: got %q; want %q", tb.logs, wantLogs)
+	}
+}
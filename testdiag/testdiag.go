@@ -3,6 +3,9 @@ package testdiag
 
 import (
 	"context"
+	"fmt"
+	"strconv"
+	"strings"
 
 	"github.com/mutility/diag"
 )
@@ -33,6 +36,86 @@ func WithContext(ctx context.Context, tb t) diag.Context {
 }
 
 func (d testDiag) Debug(args ...interface{})   { d.t.Helper(); d.t.Log(args...) }
+func (d testDiag) Info(args ...interface{})    { d.t.Helper(); d.t.Log(args...) }
 func (d testDiag) Print(args ...interface{})   { d.t.Helper(); d.t.Log(args...) }
 func (d testDiag) Warning(args ...interface{}) { d.t.Helper(); d.t.Log(args...) }
 func (d testDiag) Error(args ...interface{})   { d.t.Helper(); d.t.Log(args...) }
+
+func (d testDiag) DebugAt(file string, line, col int, args ...interface{}) {
+	d.t.Helper()
+	d.logAt(file, line, col, args)
+}
+
+func (d testDiag) DebugAtf(file string, line, col int, format string, args ...interface{}) {
+	d.t.Helper()
+	d.logAt(file, line, col, []interface{}{fmt.Sprintf(format, args...)})
+}
+
+func (d testDiag) InfoAt(file string, line, col int, args ...interface{}) {
+	d.t.Helper()
+	d.logAt(file, line, col, args)
+}
+
+func (d testDiag) InfoAtf(file string, line, col int, format string, args ...interface{}) {
+	d.t.Helper()
+	d.logAt(file, line, col, []interface{}{fmt.Sprintf(format, args...)})
+}
+
+func (d testDiag) PrintAt(file string, line, col int, args ...interface{}) {
+	d.t.Helper()
+	d.logAt(file, line, col, args)
+}
+
+func (d testDiag) PrintAtf(file string, line, col int, format string, args ...interface{}) {
+	d.t.Helper()
+	d.logAt(file, line, col, []interface{}{fmt.Sprintf(format, args...)})
+}
+
+func (d testDiag) WarningAt(file string, line, col int, args ...interface{}) {
+	d.t.Helper()
+	d.logAt(file, line, col, args)
+}
+
+func (d testDiag) WarningAtf(file string, line, col int, format string, args ...interface{}) {
+	d.t.Helper()
+	d.logAt(file, line, col, []interface{}{fmt.Sprintf(format, args...)})
+}
+
+func (d testDiag) ErrorAt(file string, line, col int, args ...interface{}) {
+	d.t.Helper()
+	d.logAt(file, line, col, args)
+}
+
+func (d testDiag) ErrorAtf(file string, line, col int, format string, args ...interface{}) {
+	d.t.Helper()
+	d.logAt(file, line, col, []interface{}{fmt.Sprintf(format, args...)})
+}
+
+// attrT is implemented by testing.TB since Go 1.25.
+type attrT interface {
+	Attr(key, value string)
+}
+
+// logAt logs args prefixed with the location from diag.FormatAt. If tb
+// supports test attributes, it also records the location as attributes,
+// which like FormatAtBracket stop at the first zero value. Attributes belong
+// to the whole test, so they only reflect its most recent location; the
+// logged line remains the record of each message's location. File names that
+// Attr would reject, containing a carriage return or newline, are not
+// recorded as attributes.
+func (d testDiag) logAt(file string, line, col int, args []interface{}) {
+	d.t.Helper()
+	if at, ok := d.t.(attrT); ok && file != "" && !strings.ContainsAny(file, "\r\n") {
+		at.Attr("file", file)
+		if line != 0 {
+			at.Attr("line", strconv.Itoa(line))
+			if col != 0 {
+				at.Attr("col", strconv.Itoa(col))
+			}
+		}
+	}
+	if loc := diag.FormatAt(file, line, col); loc != "" {
+		args = append([]interface{}{loc}, args...)
+	}
+	d.t.Log(args...)
+}
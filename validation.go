@@ -0,0 +1,67 @@
+package diag
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// ReportValidation emits an Error for each entry in errs, which maps a field
+// of the struct v to the message describing why it is invalid. Keys match a
+// field by its json tag, its yaml tag, or its Go name, and the error is
+// reported using the field's display name: its json tag, its yaml tag, or its
+// Go name, in that order of preference. Errors are emitted in field order;
+// keys that match no field follow, sorted, under their own name.
+//
+// v may be a struct or a pointer to one. Embedded structs are not searched.
+func ReportValidation(d Interface, v interface{}, errs map[string]string) {
+	if h := thelper(d); h != nil {
+		h()
+	}
+	done := make(map[string]bool, len(errs))
+	rt := reflect.TypeOf(v)
+	for rt != nil && rt.Kind() == reflect.Ptr {
+		rt = rt.Elem()
+	}
+	if rt != nil && rt.Kind() == reflect.Struct {
+		for i := 0; i < rt.NumField(); i++ {
+			f := rt.Field(i)
+			names := []string{tagName(f, "json"), tagName(f, "yaml"), f.Name}
+			for _, name := range names {
+				if msg, ok := errs[name]; ok && !done[name] && name != "" {
+					done[name] = true
+					Errorf(d, "%s: %s", displayName(names), msg)
+					break
+				}
+			}
+		}
+	}
+	var rest []string
+	for name := range errs {
+		if !done[name] {
+			rest = append(rest, name)
+		}
+	}
+	sort.Strings(rest)
+	for _, name := range rest {
+		Errorf(d, "%s: %s", name, errs[name])
+	}
+}
+
+// tagName returns the name given to f by its key tag, or "" if there is none.
+func tagName(f reflect.StructField, key string) string {
+	name := strings.SplitN(f.Tag.Get(key), ",", 2)[0]
+	if name == "-" {
+		return ""
+	}
+	return name
+}
+
+func displayName(names []string) string {
+	for _, name := range names {
+		if name != "" {
+			return name
+		}
+	}
+	return ""
+}
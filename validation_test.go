@@ -0,0 +1,42 @@
+package diag_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mutility/diag"
+)
+
+func TestReportValidation(t *testing.T) {
+	type config struct {
+		Host    string `json:"host"`
+		Port    int    `yaml:"port,omitempty"`
+		Timeout int
+		Secret  string `json:"-"`
+	}
+
+	sb := &strings.Builder{}
+	d := diag.NewWriter(sb)
+	diag.ReportValidation(d, &config{}, map[string]string{
+		"Timeout": "must be positive",
+		"port":    "out of range",
+		"Host":    "is required", // the Go name, reported by its json name
+		"missing": "is unknown",
+		"Secret":  "is too short",
+	})
+
+	want := "host: is required\n" +
+		"port: out of range\n" +
+		"Timeout: must be positive\n" +
+		"Secret: is too short\n" +
+		"missing: is unknown\n"
+	if got := sb.String(); got != want {
+		t.Errorf("got %q; want %q", got, want)
+	}
+
+	sb.Reset()
+	diag.ReportValidation(d, nil, map[string]string{"b": "two", "a": "one"})
+	if got, want := sb.String(), "a: one\nb: two\n"; got != want {
+		t.Errorf("nil: got %q; want %q", got, want)
+	}
+}
@@ -0,0 +1,49 @@
+package diag
+
+import "sync"
+
+// VerboseOnError holds diagnostics back until an error is issued. See
+// NewVerboseOnError.
+type VerboseOnError struct {
+	mu     sync.Mutex
+	inner  Interface
+	held   []Diagnostic
+	failed bool
+}
+
+// NewVerboseOnError creates an Interface that silently holds messages of all
+// levels. Upon the first error, it replays everything held to inner, and then
+// forwards all further messages directly. This keeps successful runs quiet
+// while still showing the full trace, including debug messages, for a failure.
+//
+// Call Discard once finished to release the held messages if no error
+// occurred.
+func NewVerboseOnError(inner Interface) (*VerboseOnError, Interface) {
+	v := &VerboseOnError{inner: inner}
+	return v, &funnel{emit: v.emit}
+}
+
+func (v *VerboseOnError) emit(m Diagnostic) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if !v.failed {
+		v.held = append(v.held, m)
+		if m.Level < LevelError {
+			return
+		}
+		v.failed = true
+		for _, m := range v.held {
+			forward(v.inner, m)
+		}
+		v.held = nil
+		return
+	}
+	forward(v.inner, m)
+}
+
+// Discard drops any held messages. It has no effect after an error.
+func (v *VerboseOnError) Discard() {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.held = nil
+}
@@ -0,0 +1,39 @@
+package diag_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mutility/diag"
+)
+
+func TestVerboseOnError(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		sb := &strings.Builder{}
+		v, d := diag.NewVerboseOnError(diag.NewWriterDebug(sb))
+		diag.Debug(d, "debug")
+		diag.Print(d, "print")
+		diag.Warning(d, "warning")
+		v.Discard()
+		if got := sb.String(); got != "" {
+			t.Errorf("got %q; want nothing", got)
+		}
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		sb := &strings.Builder{}
+		v, d := diag.NewVerboseOnError(diag.NewWriterDebug(sb))
+		diag.Debug(d, "debug")
+		diag.Printf(d, "print %d", 1)
+		if got := sb.String(); got != "" {
+			t.Errorf("before error: got %q; want nothing", got)
+		}
+		diag.ErrorAt(d, "fn.go", 10, 3, "error")
+		diag.Warning(d, "after")
+		v.Discard()
+		want := "debug\nprint 1\n[fn.go:10.3] error\nafter\n"
+		if got := sb.String(); got != want {
+			t.Errorf("got %q; want %q", got, want)
+		}
+	})
+}
@@ -0,0 +1,52 @@
+package diag
+
+// WarningsAsErrors creates an Interface that reissues each warning on d as an
+// error, as with a -Werror flag, and forwards all other messages unchanged.
+// Messages are reissued through the public Error functions, so d's fallbacks
+// and masks apply as usual, and a Counter wrapped by the result counts the
+// warnings as errors.
+//
+// Groups and masks are passed through to d.
+func WarningsAsErrors(d Interface) Interface {
+	w := &warningsAsErrors{inner: d}
+	w.funnel.emit = w.emit
+	return w
+}
+
+type warningsAsErrors struct {
+	funnel
+	inner Interface
+}
+
+func (w *warningsAsErrors) emit(m Diagnostic) {
+	if m.Level == LevelWarning {
+		m.Level = LevelError
+	}
+	forward(w.inner, m)
+}
+
+// Group begins a group on the underlying Interface, and runs fn against an
+// Interface that reissues its warnings like w.
+func (w *warningsAsErrors) Group(title string, fn func(Interface)) {
+	if h := thelper(w.inner); h != nil {
+		h()
+	}
+	Group(w.inner, title, func(g Interface) {
+		fn(WarningsAsErrors(g))
+	})
+}
+
+// MaskValue masks v on the underlying Interface.
+func (w *warningsAsErrors) MaskValue(v string) {
+	MaskValue(w.inner, v)
+}
+
+// UnmaskValue unmasks v on the underlying Interface.
+func (w *warningsAsErrors) UnmaskValue(v string) {
+	UnmaskValue(w.inner, v)
+}
+
+// ClearMasks clears the masks of the underlying Interface.
+func (w *warningsAsErrors) ClearMasks() {
+	ClearMasks(w.inner)
+}
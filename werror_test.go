@@ -0,0 +1,55 @@
+package diag_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mutility/diag"
+)
+
+func TestWarningsAsErrors(t *testing.T) {
+	e, w, p := &strings.Builder{}, &strings.Builder{}, &strings.Builder{}
+	c := diag.NewCounter(diag.NewWriters5(e, w, p, p, p))
+	d := diag.WarningsAsErrors(c)
+	diag.MaskValue(d, "secret")
+	t.Cleanup(func() { diag.Forget(d) })
+	diag.Warning(d, "w", "secret")
+	diag.Warningf(d, "w %d", 1)
+	diag.WarningAt(d, "fn.go", 1, 2, "w")
+	diag.WarningAtf(d, "fn.go", 3, 4, "w %d", 2)
+	diag.Debug(d, "d")
+	diag.Print(d, "p")
+	diag.Error(d, "e")
+
+	wantErr := "w ***\nw 1\n[fn.go:1.2] w\n[fn.go:3.4] w 2\ne\n"
+	if got := e.String(); got != wantErr {
+		t.Errorf("errors: got %q; want %q", got, wantErr)
+	}
+	if got := w.String(); got != "" {
+		t.Errorf("warnings: got %q; want none", got)
+	}
+	if got, want := p.String(), "d\np\n"; got != want {
+		t.Errorf("others: got %q; want %q", got, want)
+	}
+	if c.Errors() != 5 || c.Warnings() != 0 {
+		t.Errorf("counted %d errors, %d warnings; want 5, 0", c.Errors(), c.Warnings())
+	}
+}
+
+func TestWarningsAsErrorsMasks(t *testing.T) {
+	sb := &strings.Builder{}
+	inner := diag.NewWriter(sb)
+	d := diag.WarningsAsErrors(inner)
+	diag.MaskValue(d, "one")
+	diag.MaskValue(d, "two")
+	diag.UnmaskValue(d, "one")
+	diag.Print(d, "one two")
+	diag.ClearMasks(d)
+	diag.Print(d, "one two")
+	if got, want := sb.String(), "one ***\none two\n"; got != want {
+		t.Errorf("got %q; want %q", got, want)
+	}
+	if diag.HasMasker(inner) {
+		t.Error("masks remain on inner")
+	}
+}
@@ -1,72 +1,140 @@
 package diag
 
 import (
+	"bytes"
 	"fmt"
 	"io"
+	"sync"
 )
 
 // NewWriter creates an Interface wrapper for an io.Writer. It will write
-// Error and Warning messages to w, and discard Debug messages.
-func NewWriter(w io.Writer) *wrap {
-	return &wrap{io.Discard, w, w, w}
+// Error, Warning and Info messages to w, and discard Debug messages.
+//
+// Like the other writer constructors, it accepts WithErrorHandler to receive
+// errors from failed writes, which are otherwise discarded.
+func NewWriter(w io.Writer, opts ...Option) *wrap {
+	return NewWriters5(w, w, w, w, io.Discard, opts...)
 }
 
 // NewWriterDebug creates an Interface wrapper for an io.Writer. It will write
-// Error, Warning and Debug messages to w.
-func NewWriterDebug(w io.Writer) *wrap {
-	return &wrap{w, w, w, w}
+// Error, Warning, Info and Debug messages to w.
+func NewWriterDebug(w io.Writer, opts ...Option) *wrap {
+	return NewWriters5(w, w, w, w, w, opts...)
+}
+
+// NewWriterLevel creates an Interface wrapper for an io.Writer. It will write
+// messages at least as severe as min to w, and discard the rest.
+func NewWriterLevel(w io.Writer, min Level, opts ...Option) *wrap {
+	at := func(l Level) io.Writer {
+		if l < min {
+			return io.Discard
+		}
+		return w
+	}
+	return NewWriters5(at(LevelError), at(LevelWarning), at(LevelPrint), at(LevelInfo), at(LevelDebug), opts...)
 }
 
 // NewWriters creates an Interface wrapper for io.Writers. It will write Error,
-// Warning/Print and Debug messages to their respective streams.
-func NewWriters(errors, warnings, debugs io.Writer) *wrap {
-	return NewWriters4(errors, warnings, warnings, debugs)
+// Warning/Print/Info and Debug messages to their respective streams.
+func NewWriters(errors, warnings, debugs io.Writer, opts ...Option) *wrap {
+	return NewWriters4(errors, warnings, warnings, debugs, opts...)
 }
 
 // NewWriters4 creates an Interface wrapper for io.Writers. It will write Error,
-// Warning, Print and Debug messages to their respective streams.
-func NewWriters4(errors, warnings, prints, debugs io.Writer) *wrap {
-	return &wrap{wd: debugs, wp: prints, ww: warnings, we: errors}
+// Warning, Print/Info and Debug messages to their respective streams.
+func NewWriters4(errors, warnings, prints, debugs io.Writer, opts ...Option) *wrap {
+	return NewWriters5(errors, warnings, prints, prints, debugs, opts...)
+}
+
+// NewWriters5 creates an Interface wrapper for io.Writers. It will write Error,
+// Warning, Print, Info and Debug messages to their respective streams.
+func NewWriters5(errors, warnings, prints, infos, debugs io.Writer, opts ...Option) *wrap {
+	return &wrap{wd: debugs, wi: infos, wp: prints, ww: warnings, we: errors, opts: newOptions(opts)}
 }
 
 type wrap struct {
-	wd, wp, ww, we io.Writer
+	wd, wi, wp, ww, we io.Writer
+	opts               options
 }
 
 func (w *wrap) Debug(a ...interface{}) {
-	fmt.Fprintln(w.wd, a...)
+	_, err := fmt.Fprintln(w.wd, a...)
+	w.opts.error(err)
+}
+
+func (w *wrap) Info(a ...interface{}) {
+	_, err := fmt.Fprintln(w.wi, a...)
+	w.opts.error(err)
 }
 
 func (w *wrap) Print(a ...interface{}) {
-	fmt.Fprintln(w.wp, a...)
+	_, err := fmt.Fprintln(w.wp, a...)
+	w.opts.error(err)
 }
 
 func (w *wrap) Warning(a ...interface{}) {
-	fmt.Fprintln(w.ww, a...)
+	_, err := fmt.Fprintln(w.ww, a...)
+	w.opts.error(err)
 }
 
 func (w *wrap) Error(a ...interface{}) {
-	fmt.Fprintln(w.we, a...)
+	_, err := fmt.Fprintln(w.we, a...)
+	w.opts.error(err)
 }
 
-// NewPrefixed returns a writer that prefixes each write with the specified
+// NewPrefixed returns a writer that prefixes each line with the specified
 // prefix. This is useful to create differentiations for a single stream, e.g.:
 //
 //     log := NewWriters(NewPrefixed(w, "E:"), NewPrefixed(w, "W:"), io.Discard)
 //
+// A line written across several writes is prefixed only once. The writer may
+// be used concurrently, such as by several streams of a shared Interface;
+// each write is passed to w whole.
 func NewPrefixed(w io.Writer, prefix string) *prefixWriter {
-	return &prefixWriter{w, prefix}
+	return &prefixWriter{w: w, p: prefix}
 }
 
 type prefixWriter struct {
-	w io.Writer
-	p string
+	mu    sync.Mutex // guards mid, and orders writes to w with it
+	w     io.Writer
+	p     string
+	stamp func() string // if set, replaces p for each line
+	mid   bool          // the last write ended within a line
+}
+
+// NewTimestamped returns a writer that prefixes each line with the current
+// time formatted with layout, followed by a space. Like NewPrefixed, a line
+// written across several writes is prefixed only once, with the time of its
+// first write. Supply WithClock to control the time.
+func NewTimestamped(w io.Writer, layout string, opts ...Option) io.Writer {
+	o := newOptions(opts)
+	return &prefixWriter{w: w, stamp: func() string { return o.now().Format(layout) }}
 }
 
 func (w *prefixWriter) Write(b []byte) (int, error) {
-	var err error
-	if len(b) > 0 {
-		_, err = fmt.Fprintf(w.w, "%s %s", w.p, b)
+	if len(b) == 0 {
+		return 0, nil
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	var buf bytes.Buffer
+	for rest := b; len(rest) > 0; {
+		if !w.mid {
+			if w.stamp != nil {
+				buf.WriteString(w.stamp())
+			} else {
+				buf.WriteString(w.p)
+			}
+			buf.WriteByte(' ')
+		}
+		line := rest
+		if i := bytes.IndexByte(rest, '\n'); i >= 0 {
+			line = rest[:i+1]
+		}
+		buf.Write(line)
+		w.mid = line[len(line)-1] != '\n'
+		rest = rest[len(line):]
 	}
+	_, err := w.w.Write(buf.Bytes())
 	return len(b), err
 }